@@ -36,6 +36,7 @@ show_help() {
     echo "Environment variables:"
     echo "  VARTIQ_API_KEY          Vartiq API key (can be used instead of -k)"
     echo "  VARTIQ_API_URL          Vartiq API URL (can be used instead of -u)"
+    echo "  VARTIQ_TEST_POSTGRES_DSN  PostgreSQL DSN for the outbox tests (optional)"
 }
 
 # Default values
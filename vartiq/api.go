@@ -0,0 +1,94 @@
+package vartiq
+
+import (
+	"context"
+	"time"
+)
+
+// ProjectAPI is the set of project operations, implemented by *ProjectService.
+type ProjectAPI interface {
+	Create(ctx context.Context, req *CreateProjectRequest, opts ...RequestOption) (*Response[Project], error)
+	List(ctx context.Context) (*Response[[]Project], error)
+	Get(ctx context.Context, projectID string) (*Response[Project], error)
+	Update(ctx context.Context, projectID string, req *UpdateProjectRequest) (*Response[Project], error)
+	Delete(ctx context.Context, projectID string) error
+}
+
+// AppAPI is the set of app operations, implemented by *AppService.
+type AppAPI interface {
+	Create(ctx context.Context, req *CreateAppRequest, opts ...RequestOption) (*Response[App], error)
+	List(ctx context.Context, projectID string) (*Response[[]App], error)
+	Get(ctx context.Context, appID string) (*Response[App], error)
+	Update(ctx context.Context, appID string, req *UpdateAppRequest) (*Response[App], error)
+	Delete(ctx context.Context, appID string) error
+}
+
+// WebhookAPI is the set of webhook operations, implemented by *WebhookService.
+type WebhookAPI interface {
+	Create(ctx context.Context, req *CreateWebhookRequest, opts ...RequestOption) (*Response[Webhook], error)
+	GetAll(ctx context.Context, appID string) (*Response[[]Webhook], error)
+	GetOne(ctx context.Context, webhookID string) (*Response[Webhook], error)
+	Update(ctx context.Context, webhookID string, req map[string]interface{}) (*Response[Webhook], error)
+	UpdateWebhook(ctx context.Context, webhookID string, req *UpdateWebhookRequest) (*Response[Webhook], error)
+	Delete(ctx context.Context, webhookID string) error
+}
+
+// WebhookMessageAPI is the set of webhook message operations, implemented by
+// *WebhookMessageService.
+type WebhookMessageAPI interface {
+	Create(ctx context.Context, appID string, payload interface{}, opts ...RequestOption) (*Response[WebhookMessage], error)
+	CreateBatch(ctx context.Context, appID string, payloads []interface{}, opts ...RequestOption) ([]BatchResult, error)
+	List(ctx context.Context, appID string, filter *WebhookMessageFilter) (*Response[[]WebhookMessage], error)
+	Get(ctx context.Context, messageID string) (*Response[WebhookMessage], error)
+	ListAttempts(ctx context.Context, messageID string) (*Response[[]DeliveryAttempt], error)
+	Retry(ctx context.Context, messageID string, opts *RetryMessageOptions, reqOpts ...RequestOption) (*Response[RetryResult], error)
+	Replay(ctx context.Context, appID string, since, until time.Time, filter *ReplayFilter) (*ReplayResult, error)
+	WaitForDelivery(ctx context.Context, messageID string, opts *WaitOptions) (*DeliveryStatus, error)
+}
+
+// EventTypeAPI is the set of event type operations, implemented by
+// *EventTypeService.
+type EventTypeAPI interface {
+	Create(ctx context.Context, req *CreateEventTypeRequest, opts ...RequestOption) (*Response[EventType], error)
+	List(ctx context.Context, appID string) (*Response[[]EventType], error)
+	Deprecate(ctx context.Context, eventTypeID string) (*Response[EventType], error)
+}
+
+// API is the full Vartiq client surface, implemented by *Client. Depend on it
+// instead of *Client to substitute a fake in tests, e.g. from the vartiqmock
+// package.
+type API interface {
+	Projects() ProjectAPI
+	Apps() AppAPI
+	Webhooks() WebhookAPI
+	WebhookMessages() WebhookMessageAPI
+	EventTypes() EventTypeAPI
+	Verify(payload []byte, signature, secret string) ([]byte, error)
+	VerifyWithOptions(payload []byte, signature, secret string, opts VerifyOptions) ([]byte, error)
+	VerifyStandard(payload []byte, signature, secret string, opts VerifyOptions) ([]byte, error)
+	InvalidateSchemas(appID string)
+}
+
+var (
+	_ ProjectAPI        = (*ProjectService)(nil)
+	_ AppAPI            = (*AppService)(nil)
+	_ WebhookAPI        = (*WebhookService)(nil)
+	_ WebhookMessageAPI = (*WebhookMessageService)(nil)
+	_ EventTypeAPI      = (*EventTypeService)(nil)
+	_ API               = (*Client)(nil)
+)
+
+// Projects returns the project service.
+func (c *Client) Projects() ProjectAPI { return c.Project }
+
+// Apps returns the app service.
+func (c *Client) Apps() AppAPI { return c.App }
+
+// Webhooks returns the webhook service.
+func (c *Client) Webhooks() WebhookAPI { return c.Webhook }
+
+// WebhookMessages returns the webhook message service.
+func (c *Client) WebhookMessages() WebhookMessageAPI { return c.WebhookMessage }
+
+// EventTypes returns the event type service.
+func (c *Client) EventTypes() EventTypeAPI { return c.EventType }
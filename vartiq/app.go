@@ -23,64 +23,54 @@ type CreateAppRequest struct {
 	Description string `json:"description,omitempty"`
 }
 
-type CreateAppResponse struct {
-	Data    App    `json:"data"`
-	Message string `json:"message"`
-	Success bool   `json:"success"`
-}
+// CreateAppResponse is kept for compatibility; it is the same type as Response[App].
+type CreateAppResponse = Response[App]
 
-func (s *AppService) Create(ctx context.Context, req *CreateAppRequest) (*CreateAppResponse, error) {
-	resp := &CreateAppResponse{}
-	_, err := s.client.resty.R().
-		SetContext(ctx).
+func (s *AppService) Create(ctx context.Context, req *CreateAppRequest, opts ...RequestOption) (*Response[App], error) {
+	resp := &Response[App]{}
+	httpResp, err := s.client.newRequest(ctx, opts...).
 		SetBody(req).
 		SetResult(resp).
 		Post("/apps")
 	if err != nil {
 		return nil, err
 	}
+	if err := checkResponse(httpResp); err != nil {
+		return nil, err
+	}
+	resp.setMeta(httpResp)
 	return resp, nil
 }
 
 // List all apps for a project
-func (s *AppService) List(ctx context.Context, projectID string) (*struct {
-	Data    []App  `json:"data"`
-	Message string `json:"message"`
-	Success bool   `json:"success"`
-}, error) {
-	resp := &struct {
-		Data    []App  `json:"data"`
-		Message string `json:"message"`
-		Success bool   `json:"success"`
-	}{}
-	_, err := s.client.resty.R().
-		SetContext(ctx).
+func (s *AppService) List(ctx context.Context, projectID string) (*Response[[]App], error) {
+	resp := &Response[[]App]{}
+	httpResp, err := s.client.newRequest(ctx).
 		SetResult(resp).
 		Get("/apps?projectId=" + projectID)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkResponse(httpResp); err != nil {
+		return nil, err
+	}
+	resp.setMeta(httpResp)
 	return resp, nil
 }
 
 // Get a single app by ID
-func (s *AppService) Get(ctx context.Context, appID string) (*struct {
-	Data    App    `json:"data"`
-	Message string `json:"message"`
-	Success bool   `json:"success"`
-}, error) {
-	resp := &struct {
-		Data    App    `json:"data"`
-		Message string `json:"message"`
-		Success bool   `json:"success"`
-	}{}
-	_, err := s.client.resty.R().
-		SetContext(ctx).
+func (s *AppService) Get(ctx context.Context, appID string) (*Response[App], error) {
+	resp := &Response[App]{}
+	httpResp, err := s.client.newRequest(ctx).
 		SetResult(resp).
 		Get("/apps/" + appID)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkResponse(httpResp); err != nil {
+		return nil, err
+	}
+	resp.setMeta(httpResp)
 	return resp, nil
 }
 
@@ -91,31 +81,28 @@ type UpdateAppRequest struct {
 }
 
 // Update an app by ID
-func (s *AppService) Update(ctx context.Context, appID string, req *UpdateAppRequest) (*struct {
-	Data    App    `json:"data"`
-	Message string `json:"message"`
-	Success bool   `json:"success"`
-}, error) {
-	resp := &struct {
-		Data    App    `json:"data"`
-		Message string `json:"message"`
-		Success bool   `json:"success"`
-	}{}
-	_, err := s.client.resty.R().
-		SetContext(ctx).
+func (s *AppService) Update(ctx context.Context, appID string, req *UpdateAppRequest) (*Response[App], error) {
+	resp := &Response[App]{}
+	httpResp, err := s.client.newRequest(ctx).
 		SetBody(req).
 		SetResult(resp).
 		Put("/apps/" + appID)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkResponse(httpResp); err != nil {
+		return nil, err
+	}
+	resp.setMeta(httpResp)
 	return resp, nil
 }
 
 // Delete an app by ID
 func (s *AppService) Delete(ctx context.Context, appID string) error {
-	_, err := s.client.resty.R().
-		SetContext(ctx).
+	httpResp, err := s.client.newRequest(ctx).
 		Delete("/apps/" + appID)
-	return err
+	if err != nil {
+		return err
+	}
+	return checkResponse(httpResp)
 }
@@ -7,49 +7,161 @@ import (
 	"crypto/sha256"
 	"crypto/subtle"
 	"encoding/hex"
-	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 )
 
+const (
+	// DefaultBaseURL is the Vartiq API endpoint used when no base URL is configured.
+	DefaultBaseURL = "https://api.us.vartiq.com"
+
+	// DefaultUserAgent is the User-Agent header sent when none is configured.
+	DefaultUserAgent = "vartiq-go-sdk"
+)
+
 // Client represents a Vartiq API client
 type Client struct {
 	baseURL string
 	apiKey  string
 	resty   *resty.Client
+	logger  *slog.Logger
+	schemas *schemaCache
 
 	Project        *ProjectService
 	App            *AppService
 	Webhook        *WebhookService
 	WebhookMessage *WebhookMessageService
+	EventType      *EventTypeService
+}
+
+// clientOptions holds the settings collected from Option values before the
+// underlying HTTP client is built.
+type clientOptions struct {
+	baseURL    string
+	httpClient *http.Client
+	timeout    time.Duration
+	userAgent  string
+	retry      RetryPolicy
+
+	logger      *slog.Logger
+	logRequests bool
+	logBodies   bool
+
+	validateSchemas bool
+	schemaCacheTTL  time.Duration
+}
+
+// Option configures a Client created with NewClient.
+type Option func(*clientOptions)
+
+// WithBaseURL overrides the API endpoint. Empty values are ignored.
+func WithBaseURL(baseURL string) Option {
+	return func(o *clientOptions) {
+		if baseURL != "" {
+			o.baseURL = baseURL
+		}
+	}
+}
+
+// WithHTTPClient makes the client send requests through hc, so callers can
+// plug in their own transport, proxy settings or instrumentation.
+// The given client is copied and never modified.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(o *clientOptions) {
+		o.httpClient = hc
+	}
+}
+
+// WithTimeout sets the overall timeout for each HTTP request.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *clientOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(o *clientOptions) {
+		o.userAgent = userAgent
+	}
 }
 
 // New creates a new Vartiq API client. If baseURL is not provided, it defaults to https://api.us.vartiq.com
 func New(apiKey string, baseURL ...string) *Client {
-	url := "https://api.us.vartiq.com"
-	if len(baseURL) > 0 && baseURL[0] != "" {
-		url = baseURL[0]
+	var opts []Option
+	if len(baseURL) > 0 {
+		opts = append(opts, WithBaseURL(baseURL[0]))
+	}
+	return NewClient(apiKey, opts...)
+}
+
+// NewClient creates a new Vartiq API client configured by opts.
+// Example:
+//
+//	client := vartiq.NewClient("YOUR_API_KEY",
+//	    vartiq.WithHTTPClient(&http.Client{Transport: myTransport}),
+//	    vartiq.WithTimeout(10*time.Second),
+//	)
+func NewClient(apiKey string, opts ...Option) *Client {
+	o := &clientOptions{
+		baseURL:   DefaultBaseURL,
+		userAgent: DefaultUserAgent,
+		retry:     DefaultRetryPolicy(),
 	}
-	r := resty.New().SetBaseURL(url).SetHeader("x-api-key", apiKey)
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var r *resty.Client
+	if o.httpClient != nil {
+		// Copy so that setting a timeout or default transport never leaks
+		// into a client shared with the rest of the application.
+		hc := *o.httpClient
+		r = resty.NewWithClient(&hc)
+	} else {
+		r = resty.New()
+	}
+	r.SetBaseURL(o.baseURL).
+		SetHeader("x-api-key", apiKey).
+		SetHeader("User-Agent", o.userAgent)
+	if o.timeout > 0 {
+		r.SetTimeout(o.timeout)
+	}
+	o.retry.apply(r)
+
 	c := &Client{
-		baseURL: url,
+		baseURL: o.baseURL,
 		apiKey:  apiKey,
 		resty:   r,
+		logger:  o.logger,
+	}
+	if o.validateSchemas {
+		c.schemas = newSchemaCache(o.schemaCacheTTL)
+	}
+	if c.logger == nil {
+		c.logger = slog.New(discardHandler{})
 	}
+	c.installLogging(r, o)
 	c.Project = &ProjectService{client: c}
 	c.App = &AppService{client: c}
 	c.Webhook = &WebhookService{client: c}
 	c.WebhookMessage = &WebhookMessageService{client: c}
+	c.EventType = &EventTypeService{client: c}
 	return c
 }
 
 // Verify checks the signature of a webhook payload.
 // It takes the raw payload bytes, the signature string from the header, and the webhook secret.
-// It returns the payload bytes if the signature is valid, otherwise returns an error.
+// It returns the payload bytes if the signature is valid, otherwise returns an
+// error matching ErrSignatureInvalid. The signature covers the payload only;
+// use VerifyWithOptions for signatures that also cover a timestamp.
 func (c *Client) Verify(payload []byte, signature, secret string) ([]byte, error) {
 	if signature == "" {
-		return nil, errors.New("signature header is missing")
+		return nil, fmt.Errorf("%w: signature header is missing", ErrSignatureInvalid)
 	}
 
 	mac := hmac.New(sha256.New, []byte(secret))
@@ -59,12 +171,12 @@ func (c *Client) Verify(payload []byte, signature, secret string) ([]byte, error
 	// Assuming the signature is hex encoded
 	receivedSignature, err := hex.DecodeString(signature)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode signature: %w", err)
+		return nil, fmt.Errorf("%w: failed to decode signature: %v", ErrSignatureInvalid, err)
 	}
 
 	// Use constant-time comparison to prevent timing attacks
 	if subtle.ConstantTimeCompare(receivedSignature, expectedSignature) != 1 {
-		return nil, errors.New("signature verification failed")
+		return nil, fmt.Errorf("%w: signature verification failed", ErrSignatureInvalid)
 	}
 
 	return payload, nil
@@ -1,7 +1,11 @@
 package vartiq
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -24,3 +28,34 @@ func TestNewClient_CustomBaseURL(t *testing.T) {
 	client := New(apiKey, baseURL)
 	assert.Equal(t, baseURL, client.baseURL)
 }
+
+func TestNewClient_Options(t *testing.T) {
+	var gotUA string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUA = r.Header.Get("User-Agent")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[],"message":"ok","success":true}`))
+	}))
+	defer srv.Close()
+
+	hc := &http.Client{}
+	client := NewClient("test-key",
+		WithBaseURL(srv.URL),
+		WithHTTPClient(hc),
+		WithTimeout(5*time.Second),
+		WithUserAgent("my-service/1.0"),
+	)
+	assert.Equal(t, srv.URL, client.baseURL)
+	assert.Equal(t, 5*time.Second, client.resty.GetClient().Timeout)
+	assert.Zero(t, hc.Timeout, "caller's http.Client must not be modified")
+
+	_, err := client.Project.List(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "my-service/1.0", gotUA)
+}
+
+func TestNewClient_Defaults(t *testing.T) {
+	client := NewClient("test-key", WithBaseURL(""))
+	assert.Equal(t, DefaultBaseURL, client.baseURL)
+	assert.Equal(t, DefaultUserAgent, client.resty.Header.Get("User-Agent"))
+}
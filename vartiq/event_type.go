@@ -0,0 +1,134 @@
+package vartiq
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq/schema"
+)
+
+// EventTypeService manages the event types registered on an app. Messages
+// are tagged with an event type using WithEventType, and webhooks created
+// with CreateWebhookRequest.EventTypes receive only messages of those types.
+type EventTypeService struct {
+	client *Client
+}
+
+// EventType is a kind of event an app sends, such as "invoice.paid".
+type EventType struct {
+	ID          string `json:"id"`
+	AppID       string `json:"appId"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Deprecated reports whether the event type was deprecated. Existing
+	// subscriptions keep receiving its messages, but new webhooks cannot
+	// subscribe to it.
+	Deprecated bool   `json:"deprecated"`
+	CreatedAt  string `json:"createdAt"`
+	UpdatedAt  string `json:"updatedAt"`
+	// Schema is the JSON Schema (draft 2020-12) payloads of this type must
+	// match, or empty. Compile it with the schema package to validate
+	// payloads on the receiving side.
+	Schema json.RawMessage `json:"schema,omitempty"`
+}
+
+// CreateEventTypeRequest is used for registering an event type. Name must be
+// unique within the app.
+type CreateEventTypeRequest struct {
+	AppID       string `json:"appId"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Schema is an optional JSON Schema (draft 2020-12) for the payloads of
+	// this type, checked by clients created WithSchemaValidation.
+	Schema json.RawMessage `json:"schema,omitempty"`
+}
+
+func (r *CreateEventTypeRequest) validate() error {
+	if err := requireFields("appId and name are required",
+		field{"appId", r.AppID}, field{"name", r.Name}); err != nil {
+		return err
+	}
+	if hasSchema(r.Schema) {
+		if _, err := schema.Compile(r.Schema); err != nil {
+			return &ValidationError{
+				Message: "invalid event type schema: " + err.Error(),
+				Fields:  []FieldError{{Field: "schema", Message: err.Error()}},
+			}
+		}
+	}
+	return nil
+}
+
+// WithEventType sets the event type of the messages created by
+// WebhookMessage.Create and CreateBatch. The event type must be registered
+// on the app; webhooks subscribed to specific event types receive only the
+// messages of those types. Other calls ignore it.
+func WithEventType(name string) RequestOption {
+	return func(o *requestOptions) {
+		o.eventType = name
+	}
+}
+
+// Create registers an event type on an app. A client created with
+// WithSchemaValidation drops the app's cached schemas, so the next message
+// sent to the app is checked against the current ones.
+// Example:
+//
+//	eventType, err := client.EventType.Create(ctx, &vartiq.CreateEventTypeRequest{
+//	    AppID:       "APP_ID",
+//	    Name:        "invoice.paid",
+//	    Description: "An invoice was paid in full",
+//	})
+func (s *EventTypeService) Create(ctx context.Context, req *CreateEventTypeRequest, opts ...RequestOption) (*Response[EventType], error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	resp := &Response[EventType]{}
+	httpResp, err := s.client.newRequest(ctx, opts...).
+		SetBody(req).
+		SetResult(resp).
+		Post("/event-types")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(httpResp); err != nil {
+		return nil, err
+	}
+	s.client.InvalidateSchemas(req.AppID)
+	resp.setMeta(httpResp)
+	return resp, nil
+}
+
+// List all event types of an app, including deprecated ones
+func (s *EventTypeService) List(ctx context.Context, appID string) (*Response[[]EventType], error) {
+	resp := &Response[[]EventType]{}
+	httpResp, err := s.client.newRequest(ctx).
+		SetResult(resp).
+		Get("/event-types?appId=" + appID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(httpResp); err != nil {
+		return nil, err
+	}
+	resp.setMeta(httpResp)
+	return resp, nil
+}
+
+// Deprecate marks an event type as deprecated. Deprecating an event type
+// twice is not an error.
+func (s *EventTypeService) Deprecate(ctx context.Context, eventTypeID string) (*Response[EventType], error) {
+	resp := &Response[EventType]{}
+	httpResp, err := s.client.newRequest(ctx).
+		SetResult(resp).
+		Post("/event-types/" + eventTypeID + "/deprecate")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(httpResp); err != nil {
+		return nil, err
+	}
+	resp.setMeta(httpResp)
+	return resp, nil
+}
@@ -0,0 +1,106 @@
+package vartiq
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventTypeService(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "POST /event-types":
+			var req CreateEventTypeRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, CreateEventTypeRequest{AppID: "app-1", Name: "invoice.paid", Description: "Paid"}, req)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"success":true,"data":{"id":"et-1","appId":"app-1","name":"invoice.paid","description":"Paid"}}`))
+		case "GET /event-types":
+			_, _ = w.Write([]byte(`{"success":true,"data":[{"id":"et-1","name":"invoice.paid"},{"id":"et-2","name":"user.deleted","deprecated":true}]}`))
+		case "POST /event-types/et-2/deprecate":
+			_, _ = w.Write([]byte(`{"success":true,"data":{"id":"et-2","name":"user.deleted","deprecated":true}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"success":false,"message":"Route not found"}`))
+		}
+	}))
+	defer srv.Close()
+	client := NewClient("test-key", WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{}))
+	ctx := context.Background()
+
+	created, err := client.EventType.Create(ctx, &CreateEventTypeRequest{AppID: "app-1", Name: "invoice.paid", Description: "Paid"})
+	require.NoError(t, err)
+	assert.Equal(t, "et-1", created.Data.ID)
+	assert.Equal(t, http.StatusCreated, created.StatusCode)
+
+	list, err := client.EventTypes().List(ctx, "app-1")
+	require.NoError(t, err)
+	require.Len(t, list.Data, 2)
+	assert.False(t, list.Data[0].Deprecated)
+	assert.True(t, list.Data[1].Deprecated)
+
+	deprecated, err := client.EventType.Deprecate(ctx, "et-2")
+	require.NoError(t, err)
+	assert.True(t, deprecated.Data.Deprecated)
+
+	_, err = client.EventType.Deprecate(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.Equal(t, []string{
+		"POST /event-types",
+		"GET /event-types?appId=app-1",
+		"POST /event-types/et-2/deprecate",
+		"POST /event-types/missing/deprecate",
+	}, requests)
+}
+
+func TestEventTypeService_CreateValidates(t *testing.T) {
+	client := NewClient("test-key", WithBaseURL("http://127.0.0.1:0"))
+	_, err := client.EventType.Create(context.Background(), &CreateEventTypeRequest{AppID: "app-1"})
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, []FieldError{{Field: "name", Message: "is required"}}, verr.Fields)
+}
+
+func TestWithEventType(t *testing.T) {
+	var bodies []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		bodies = append(bodies, body)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/webhook-messages/batch" {
+			_, _ = w.Write([]byte(`{"success":true,"data":{"results":[{"webhookMessages":[{"id":"m2","eventType":"invoice.paid","payload":"{}"}]}]}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"success":true,"data":{"webhookMessages":[{"id":"m1","eventType":"invoice.paid","payload":"{}"}]}}`))
+	}))
+	defer srv.Close()
+	client := NewClient("test-key", WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{}))
+	ctx := context.Background()
+
+	msg, err := client.WebhookMessage.Create(ctx, "app-1", map[string]string{}, WithEventType("invoice.paid"))
+	require.NoError(t, err)
+	assert.Equal(t, "invoice.paid", msg.Data.EventType)
+
+	results, err := client.WebhookMessage.CreateBatch(ctx, "app-1", []interface{}{map[string]string{}}, WithEventType("invoice.paid"))
+	require.NoError(t, err)
+	require.NoError(t, results[0].Err)
+	assert.Equal(t, "invoice.paid", results[0].Message.EventType)
+
+	_, err = client.WebhookMessage.Create(ctx, "app-1", map[string]string{})
+	require.NoError(t, err)
+
+	require.Len(t, bodies, 3)
+	assert.Equal(t, "invoice.paid", bodies[0]["eventType"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"eventType": "invoice.paid", "payload": map[string]interface{}{}}}, bodies[1]["messages"])
+	assert.NotContains(t, bodies[2], "eventType")
+}
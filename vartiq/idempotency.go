@@ -0,0 +1,72 @@
+package vartiq
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// idempotentReplayedHeader is set by the API when a response was replayed
+// from an earlier request with the same idempotency key.
+const idempotentReplayedHeader = "Idempotent-Replayed"
+
+// RequestOption customizes a single API call.
+type RequestOption func(*requestOptions)
+
+type requestOptions struct {
+	idempotencyKey string
+	eventType      string
+}
+
+// WithIdempotencyKey sends key in the Idempotency-Key header so the API
+// performs the operation at most once, no matter how often the request is
+// sent. Requests carrying a key are also retried automatically.
+// The same key must not be reused for a different request.
+func WithIdempotencyKey(key string) RequestOption {
+	return func(o *requestOptions) {
+		o.idempotencyKey = key
+	}
+}
+
+// NewIdempotencyKey returns a random UUID (version 4) suitable for
+// WithIdempotencyKey. Persist it alongside the event if the call may be
+// repeated after a restart.
+func NewIdempotencyKey() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("vartiq: generating idempotency key: %v", err))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// newRequest starts a request bound to ctx with the per-call options applied.
+func (c *Client) newRequest(ctx context.Context, opts ...RequestOption) *resty.Request {
+	o := applyOptions(opts)
+	req := c.resty.R().SetContext(ctx)
+	if o.idempotencyKey != "" {
+		req.SetHeader(IdempotencyKeyHeader, o.idempotencyKey)
+	}
+	return req
+}
+
+// applyOptions collects opts, later options overriding earlier ones.
+func applyOptions(opts []RequestOption) *requestOptions {
+	o := &requestOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// idempotencyResult reports the key sent with resp's request and whether the
+// API replayed a stored result for it.
+func idempotencyResult(resp *resty.Response) (key string, replayed bool) {
+	key = resp.Request.Header.Get(IdempotencyKeyHeader)
+	replayed = strings.EqualFold(resp.Header().Get(idempotentReplayedHeader), "true")
+	return key, replayed
+}
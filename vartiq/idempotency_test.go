@@ -0,0 +1,87 @@
+package vartiq
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const webhookMessageCreatedBody = `{
+	"data": {"webhookMessages": [{"id": "msg-1", "app": "app-1", "payload": "{\"hello\":\"world\"}", "isDelivered": false}]},
+	"message": "created",
+	"success": true
+}`
+
+func TestNewIdempotencyKey(t *testing.T) {
+	key := NewIdempotencyKey()
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), key)
+	assert.NotEqual(t, key, NewIdempotencyKey())
+}
+
+func TestWebhookMessageCreate_IdempotencyKey(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		keys []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys = append(keys, r.Header.Get(IdempotencyKeyHeader))
+		attempt := len(keys)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if attempt == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set(idempotentReplayedHeader, "true")
+		_, _ = w.Write([]byte(webhookMessageCreatedBody))
+	}))
+	defer srv.Close()
+
+	policy := DefaultRetryPolicy()
+	policy.MinBackoff = time.Millisecond
+	client := NewClient("test-key", WithBaseURL(srv.URL), WithRetryPolicy(policy))
+
+	t.Run("generated key is reused across retries", func(t *testing.T) {
+		resp, err := client.WebhookMessage.Create(context.Background(), "app-1", map[string]interface{}{"hello": "world"})
+		require.NoError(t, err)
+		require.Len(t, keys, 2)
+		assert.NotEmpty(t, keys[0])
+		assert.Equal(t, keys[0], keys[1])
+		assert.Equal(t, keys[0], resp.IdempotencyKey)
+		assert.True(t, resp.Replayed)
+	})
+
+	t.Run("explicit key", func(t *testing.T) {
+		keys = nil
+		resp, err := client.WebhookMessage.Create(context.Background(), "app-1", map[string]interface{}{"hello": "world"}, WithIdempotencyKey("event-42"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"event-42", "event-42"}, keys)
+		assert.Equal(t, "event-42", resp.IdempotencyKey)
+	})
+}
+
+func TestProjectCreate_WithoutIdempotencyKey(t *testing.T) {
+	var header []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Values(IdempotencyKeyHeader)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"id":"p1"},"message":"ok","success":true}`))
+	}))
+	defer srv.Close()
+
+	client := New("test-key", srv.URL)
+	resp, err := client.Project.Create(context.Background(), &CreateProjectRequest{Name: "Test"})
+	require.NoError(t, err)
+	assert.Empty(t, header)
+	assert.Empty(t, resp.IdempotencyKey)
+	assert.False(t, resp.Replayed)
+}
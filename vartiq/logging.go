@@ -0,0 +1,228 @@
+package vartiq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// redacted replaces secret values in log output.
+const redacted = "[REDACTED]"
+
+// maxLoggedBody caps the number of body bytes written to a log record.
+const maxLoggedBody = 4096
+
+// sensitiveKeys lists header names and JSON keys whose values are never
+// logged. Keys are compared after lower-casing and removing '-' and '_'.
+var sensitiveKeys = map[string]bool{
+	"xapikey":            true,
+	"apikey":             true,
+	"authorization":      true,
+	"proxyauthorization": true,
+	"cookie":             true,
+	"setcookie":          true,
+	"password":           true,
+	"secret":             true,
+	"hmacsecret":         true,
+	"token":              true,
+	"accesstoken":        true,
+	"refreshtoken":       true,
+}
+
+func isSensitive(key string) bool {
+	key = strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(key))
+	return sensitiveKeys[key]
+}
+
+// WithLogger sets the logger used for retries, transport errors and, when
+// enabled, request/response logging. By default nothing is logged.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *clientOptions) {
+		o.logger = logger
+	}
+}
+
+// WithRequestLogging logs every request and response at debug level, with
+// method, URL, status, duration and headers. Secrets are redacted.
+func WithRequestLogging() Option {
+	return func(o *clientOptions) {
+		o.logRequests = true
+	}
+}
+
+// WithBodyLogging enables request logging and also includes request and
+// response bodies, truncated and with secret fields such as hmacSecret,
+// password and apiKey redacted. Bodies may contain customer payloads.
+func WithBodyLogging() Option {
+	return func(o *clientOptions) {
+		o.logRequests = true
+		o.logBodies = true
+	}
+}
+
+// discardHandler is a slog.Handler that drops every record.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discardHandler) WithGroup(string) slog.Handler           { return d }
+
+// restyLogger forwards resty's internal messages to slog.
+type restyLogger struct {
+	logger *slog.Logger
+}
+
+func (l restyLogger) Errorf(format string, v ...interface{}) {
+	l.logger.Error(strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+func (l restyLogger) Warnf(format string, v ...interface{}) {
+	l.logger.Warn(strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+func (l restyLogger) Debugf(format string, v ...interface{}) {
+	l.logger.Debug(strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+// installLogging wires the client's logger into the resty client.
+func (c *Client) installLogging(r *resty.Client, o *clientOptions) {
+	r.SetLogger(restyLogger{logger: c.logger})
+	r.AddRetryHook(c.logRetry)
+	if !o.logRequests {
+		return
+	}
+	logBodies := o.logBodies
+	r.OnBeforeRequest(func(rc *resty.Client, req *resty.Request) error {
+		c.logRequest(rc, req, logBodies)
+		return nil
+	})
+	r.OnAfterResponse(func(_ *resty.Client, resp *resty.Response) error {
+		c.logResponse(resp, logBodies)
+		return nil
+	})
+}
+
+func (c *Client) logRetry(resp *resty.Response, err error) {
+	if resp == nil || resp.Request == nil {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("method", resp.Request.Method),
+		slog.String("url", resp.Request.URL),
+		slog.Int("attempt", resp.Request.Attempt),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	} else {
+		attrs = append(attrs, slog.Int("status", resp.StatusCode()))
+	}
+	c.logger.LogAttrs(resp.Request.Context(), slog.LevelWarn, "vartiq: retrying request", attrs...)
+}
+
+func (c *Client) logRequest(rc *resty.Client, req *resty.Request, logBodies bool) {
+	ctx := req.Context()
+	if !c.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	header := rc.Header.Clone()
+	for k, v := range req.Header {
+		header[k] = v
+	}
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("url", req.URL),
+		slog.Int("attempt", req.Attempt),
+		slog.Any("headers", redactHeader(header)),
+	}
+	if logBodies && req.Body != nil {
+		body, err := json.Marshal(req.Body)
+		if err == nil {
+			attrs = append(attrs, slog.String("body", redactBody(body)))
+		}
+	}
+	c.logger.LogAttrs(ctx, slog.LevelDebug, "vartiq: request", attrs...)
+}
+
+func (c *Client) logResponse(resp *resty.Response, logBodies bool) {
+	ctx := resp.Request.Context()
+	if !c.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("method", resp.Request.Method),
+		slog.String("url", resp.Request.URL),
+		slog.Int("status", resp.StatusCode()),
+		slog.Duration("duration", resp.Time().Round(time.Millisecond)),
+		slog.Any("headers", redactHeader(resp.Header())),
+	}
+	if logBodies {
+		attrs = append(attrs, slog.String("body", redactBody(resp.Body())))
+	}
+	c.logger.LogAttrs(ctx, slog.LevelDebug, "vartiq: response", attrs...)
+}
+
+// redactHeader returns a copy of h with sensitive values replaced.
+func redactHeader(h http.Header) http.Header {
+	out := make(http.Header, len(h))
+	for k, v := range h {
+		if isSensitive(k) {
+			out[k] = []string{redacted}
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// redactBody returns body as a string with sensitive JSON fields replaced,
+// truncated to maxLoggedBody bytes. Non-JSON bodies are only described.
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return fmt.Sprintf("[%d bytes, not JSON]", len(body))
+	}
+	out, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return fmt.Sprintf("[%d bytes]", len(body))
+	}
+	if len(out) > maxLoggedBody {
+		return string(out[:maxLoggedBody]) + "...(truncated)"
+	}
+	return string(out)
+}
+
+func redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if isSensitive(k) {
+				v[k] = redacted
+				continue
+			}
+			v[k] = redactValue(child)
+		}
+		// Custom headers are sent as {"key": ..., "value": ...} pairs.
+		if key, ok := v["key"].(string); ok && isSensitive(key) {
+			if _, ok := v["value"]; ok {
+				v["value"] = redacted
+			}
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = redactValue(child)
+		}
+		return v
+	}
+	return v
+}
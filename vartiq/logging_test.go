@@ -0,0 +1,112 @@
+package vartiq
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const webhookCreatedBody = `{
+	"data": {"id": "wh-1", "url": "https://example.com", "appId": "app-1",
+		"authMethod": {"method": "hmac", "hmacHeader": "x-sig", "hmacSecret": "super-secret-hmac"}},
+	"message": "created",
+	"success": true
+}`
+
+func newLoggingServer(t *testing.T, status int, body string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestLogging_RedactsSecrets(t *testing.T) {
+	srv := newLoggingServer(t, http.StatusOK, webhookCreatedBody)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := NewClient("secret-api-key", WithBaseURL(srv.URL), WithLogger(logger), WithBodyLogging())
+
+	_, err := client.Webhook.Create(context.Background(), &CreateWebhookRequest{
+		URL:           "https://example.com",
+		AppID:         "app-1",
+		AuthMethod:    string(AuthMethodHMAC),
+		HMACHeader:    "x-sig",
+		HMACSecret:    "super-secret-hmac",
+		CustomHeaders: []Header{{Key: "Authorization", Value: "Bearer custom-token"}},
+	})
+	require.NoError(t, err)
+
+	out := buf.String()
+	assert.Contains(t, out, "vartiq: request")
+	assert.Contains(t, out, "vartiq: response")
+	assert.Contains(t, out, redacted)
+	assert.Contains(t, out, "https://example.com")
+	assert.NotContains(t, out, "secret-api-key")
+	assert.NotContains(t, out, "super-secret-hmac")
+	assert.NotContains(t, out, "custom-token")
+}
+
+func TestLogging_BodiesOffWithoutOption(t *testing.T) {
+	srv := newLoggingServer(t, http.StatusOK, webhookCreatedBody)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := NewClient("test-key", WithBaseURL(srv.URL), WithLogger(logger), WithRequestLogging())
+
+	_, err := client.Webhook.GetOne(context.Background(), "wh-1")
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), `"status":200`)
+	assert.NotContains(t, buf.String(), `"body"`)
+}
+
+func TestLogging_OffByDefault(t *testing.T) {
+	srv := newLoggingServer(t, http.StatusOK, webhookCreatedBody)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := NewClient("test-key", WithBaseURL(srv.URL), WithLogger(logger))
+
+	_, err := client.Webhook.GetOne(context.Background(), "wh-1")
+	require.NoError(t, err)
+	assert.Empty(t, buf.String())
+}
+
+func TestLogging_Retries(t *testing.T) {
+	srv, _ := newFlakyServer(t, 1, http.StatusServiceUnavailable, nil)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	policy := DefaultRetryPolicy()
+	policy.MinBackoff = time.Millisecond
+	client := NewClient("test-key", WithBaseURL(srv.URL), WithLogger(logger), WithRetryPolicy(policy))
+
+	_, err := client.Project.Get(context.Background(), "p1")
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "vartiq: retrying request")
+	assert.Contains(t, buf.String(), `"status":503`)
+}
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{"nested secrets", `{"data":{"password":"p","userName":"u","apiKey":"k"}}`, `{"data":{"apiKey":"[REDACTED]","password":"[REDACTED]","userName":"u"}}`},
+		{"header pairs", `[{"key":"X-Api-Key","value":"k"},{"key":"X-App","value":"v"}]`, `[{"key":"X-Api-Key","value":"[REDACTED]"},{"key":"X-App","value":"v"}]`},
+		{"not JSON", `hello`, `[5 bytes, not JSON]`},
+		{"empty", ``, ``},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, redactBody([]byte(tt.body)))
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+	"github.com/vartiqhq/vartiq-go-sdk/vartiqmock"
+	_ "modernc.org/sqlite"
+)
+
+// The tests in this file run the generated SQL on real databases, which the
+// fake driver cannot vouch for.
+
+func TestOutbox_SQLite(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "outbox.db") + "?_pragma=busy_timeout(5000)"
+	db, err := sql.Open("sqlite", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	testDatabase(t, db, SQLite, DefaultTable)
+}
+
+func TestOutbox_Postgres(t *testing.T) {
+	dsn := os.Getenv("VARTIQ_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("Skipping PostgreSQL test: VARTIQ_TEST_POSTGRES_DSN not set")
+	}
+	db, err := sql.Open("pgx", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	table := fmt.Sprintf("vartiq_outbox_test_%d", time.Now().UnixNano())
+	t.Cleanup(func() {
+		_, err := db.Exec("DROP TABLE IF EXISTS " + table)
+		assert.NoError(t, err)
+	})
+	testDatabase(t, db, Postgres, table)
+}
+
+// testDatabase enqueues, claims, sends and records rows in table through
+// every statement the package generates for dialect.
+func testDatabase(t *testing.T, db *sql.DB, dialect Dialect, table string) {
+	ctx := context.Background()
+	box, err := New(db, dialect, WithTable(table))
+	require.NoError(t, err)
+	now, advance := fixedClock()
+	box.now = now
+	require.NoError(t, box.CreateTable(ctx))
+	require.NoError(t, box.CreateTable(ctx), "CreateTable is idempotent")
+
+	ids := enqueue(t, box, "app-1", "ok", "invalid", "flaky")
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	_, err = box.Enqueue(ctx, tx, "app-1", "rolled back")
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+	pending, err := box.Pending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, pending)
+
+	messages := &vartiqmock.WebhookMessageAPIMock{
+		CreateFunc: func(ctx context.Context, appID string, payload interface{}, opts ...vartiq.RequestOption) (*vartiq.Response[vartiq.WebhookMessage], error) {
+			switch string(payload.(json.RawMessage)) {
+			case `"invalid"`:
+				return nil, &vartiq.APIError{Code: 400, Message: "bad payload"}
+			case `"flaky"`:
+				return nil, &vartiq.APIError{Code: 503, Message: "unavailable"}
+			}
+			return &vartiq.Response[vartiq.WebhookMessage]{Data: vartiq.WebhookMessage{ID: "msg-ok"}}, nil
+		},
+	}
+	relay := NewRelay(box, messages, WithLease(time.Minute), WithRetryBackoff(time.Minute))
+	n, err := relay.RelayOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	ok := loadRow(t, db, dialect, table, ids[0])
+	require.NotNil(t, ok.sentAt)
+	assert.Equal(t, "msg-ok", *ok.messageID)
+	assert.Nil(t, ok.claimedUntil)
+	assert.NotEmpty(t, ok.key)
+	invalid := loadRow(t, db, dialect, table, ids[1])
+	assert.NotEqual(t, ok.key, invalid.key)
+	require.NotNil(t, invalid.failedAt)
+	assert.Equal(t, "bad payload", *invalid.lastError)
+	flaky := loadRow(t, db, dialect, table, ids[2])
+	assert.Nil(t, flaky.sentAt)
+	assert.Equal(t, now().Add(time.Minute).UnixMilli(), *flaky.claimedUntil)
+	assert.Equal(t, "unavailable", *flaky.lastError)
+	assert.EqualValues(t, 1, flaky.attempts)
+
+	// The retry is not due yet.
+	n, err = relay.RelayOnce(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	// The next attempt overruns its lease; the relay that claims the row
+	// meanwhile sends it, and the late failure is not recorded.
+	advance(time.Minute)
+	other := NewRelay(box, &vartiqmock.WebhookMessageAPIMock{
+		CreateFunc: func(context.Context, string, interface{}, ...vartiq.RequestOption) (*vartiq.Response[vartiq.WebhookMessage], error) {
+			return &vartiq.Response[vartiq.WebhookMessage]{Data: vartiq.WebhookMessage{ID: "msg-flaky"}}, nil
+		},
+	}, WithLease(time.Minute))
+	slow := NewRelay(box, &vartiqmock.WebhookMessageAPIMock{
+		CreateFunc: func(ctx context.Context, _ string, _ interface{}, _ ...vartiq.RequestOption) (*vartiq.Response[vartiq.WebhookMessage], error) {
+			advance(2 * time.Minute)
+			n, err := other.RelayOnce(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, 1, n)
+			return nil, &vartiq.APIError{Code: 400, Message: "bad payload"}
+		},
+	}, WithLease(time.Minute))
+	n, err = slow.RelayOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	flaky = loadRow(t, db, dialect, table, ids[2])
+	require.NotNil(t, flaky.sentAt)
+	assert.Nil(t, flaky.failedAt)
+	assert.Equal(t, "msg-flaky", *flaky.messageID)
+	assert.Nil(t, flaky.lastError)
+	assert.EqualValues(t, 3, flaky.attempts)
+
+	pending, err = box.Pending(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, pending)
+}
+
+// loadRow reads the row with the given ID from table.
+func loadRow(t *testing.T, db *sql.DB, dialect Dialect, table string, id int64) fakeRow {
+	t.Helper()
+	r := fakeRow{id: id}
+	query := fmt.Sprintf(`SELECT app_id, payload, idempotency_key, created_at, attempts,
+	claimed_until, sent_at, failed_at, message_id, last_error FROM %s WHERE id = %s`, table, dialect.placeholder(1))
+	err := db.QueryRow(query, id).Scan(&r.appID, &r.payload, &r.key, &r.createdAt, &r.attempts,
+		&r.claimedUntil, &r.sentAt, &r.failedAt, &r.messageID, &r.lastError)
+	require.NoError(t, err)
+	return r
+}
@@ -0,0 +1,122 @@
+package outbox
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dialect adapts the outbox queries to a database. Use Postgres or SQLite.
+type Dialect interface {
+	// placeholder returns the bind parameter for the n-th argument, from 1.
+	placeholder(n int) string
+	// idColumn is the column definition of the auto-incrementing key.
+	idColumn() string
+	// lockClause follows the subquery selecting rows to claim.
+	lockClause() string
+}
+
+type postgres struct{}
+
+func (postgres) placeholder(n int) string { return fmt.Sprintf("$%d", n) }
+func (postgres) idColumn() string         { return "id BIGSERIAL PRIMARY KEY" }
+
+// lockClause lets concurrent relays claim disjoint rows without waiting on
+// each other.
+func (postgres) lockClause() string { return " FOR UPDATE SKIP LOCKED" }
+
+type sqlite struct{}
+
+func (sqlite) placeholder(int) string { return "?" }
+func (sqlite) idColumn() string       { return "id INTEGER PRIMARY KEY AUTOINCREMENT" }
+
+// lockClause is empty: SQLite serializes writers, so the claiming UPDATE
+// already holds the database lock.
+func (sqlite) lockClause() string { return "" }
+
+var (
+	// Postgres is the dialect for PostgreSQL, using row locks with
+	// SKIP LOCKED so several relays can run side by side.
+	Postgres Dialect = postgres{}
+	// SQLite is the dialect for SQLite 3.35 or later.
+	SQLite Dialect = sqlite{}
+)
+
+// queries holds the statements for one dialect and table.
+type queries struct {
+	create  string
+	insert  string
+	claim   string
+	renew   string
+	sent    string
+	retry   string
+	failed  string
+	pending string
+}
+
+// buildQueries renders the statements for table. Times are stored as Unix
+// milliseconds so that both dialects compare them the same way.
+func buildQueries(d Dialect, table string) queries {
+	var args int
+	p := func() string {
+		args++
+		return d.placeholder(args)
+	}
+	reset := func() { args = 0 }
+
+	var q queries
+	q.create = fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	%s,
+	app_id TEXT NOT NULL,
+	payload TEXT NOT NULL,
+	idempotency_key TEXT NOT NULL UNIQUE,
+	created_at BIGINT NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	claimed_until BIGINT,
+	sent_at BIGINT,
+	failed_at BIGINT,
+	message_id TEXT,
+	last_error TEXT
+)`, table, d.idColumn())
+
+	reset()
+	q.insert = fmt.Sprintf("INSERT INTO %s (app_id, payload, idempotency_key, created_at) VALUES (%s, %s, %s, %s) RETURNING id",
+		table, p(), p(), p(), p())
+
+	// Claimed rows are leased until claimed_until; a relay that dies leaves
+	// them to be claimed again once the lease runs out.
+	reset()
+	claimedUntil, now, limit := p(), p(), p()
+	q.claim = strings.Join([]string{
+		fmt.Sprintf("UPDATE %s SET claimed_until = %s, attempts = attempts + 1", table, claimedUntil),
+		fmt.Sprintf("WHERE id IN (SELECT id FROM %s", table),
+		"WHERE sent_at IS NULL AND failed_at IS NULL",
+		fmt.Sprintf("AND (claimed_until IS NULL OR claimed_until <= %s)", now),
+		fmt.Sprintf("ORDER BY id LIMIT %s%s)", limit, d.lockClause()),
+		"RETURNING id, app_id, payload, idempotency_key, attempts",
+	}, " ")
+
+	// A relay renews the lease of each claimed row just before sending it.
+	// The renewal only applies while the row still carries the relay's own
+	// lease, so it fails for a row another relay has claimed since.
+	reset()
+	q.renew = fmt.Sprintf("UPDATE %s SET claimed_until = %s WHERE id = %s AND claimed_until = %s AND sent_at IS NULL AND failed_at IS NULL",
+		table, p(), p(), p())
+
+	// The outcome of a send is only recorded while the relay still holds
+	// the lease it sent under, so a relay whose lease ran out mid-send
+	// cannot overwrite the outcome of the relay that claimed the row next.
+	reset()
+	q.sent = fmt.Sprintf("UPDATE %s SET sent_at = %s, message_id = %s, claimed_until = NULL, last_error = NULL WHERE id = %s AND claimed_until = %s",
+		table, p(), p(), p(), p())
+
+	reset()
+	q.retry = fmt.Sprintf("UPDATE %s SET claimed_until = %s, last_error = %s WHERE id = %s AND claimed_until = %s",
+		table, p(), p(), p(), p())
+
+	reset()
+	q.failed = fmt.Sprintf("UPDATE %s SET failed_at = %s, claimed_until = NULL, last_error = %s WHERE id = %s AND claimed_until = %s",
+		table, p(), p(), p(), p())
+
+	q.pending = fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE sent_at IS NULL AND failed_at IS NULL", table)
+	return q
+}
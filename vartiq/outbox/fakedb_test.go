@@ -0,0 +1,225 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// fakeDB is an in-memory database/sql driver that understands exactly the
+// statements this package generates, so the relay can be tested without a
+// real database. It checks that every statement uses the dialect's
+// placeholders for all of its arguments.
+type fakeDB struct {
+	dialect Dialect
+
+	mu     sync.Mutex
+	nextID int64
+	rows   map[int64]*fakeRow
+	// failExec makes statements starting with the prefix fail.
+	failExec string
+}
+
+type fakeRow struct {
+	id           int64
+	appID        string
+	payload      string
+	key          string
+	createdAt    int64
+	attempts     int64
+	claimedUntil *int64
+	sentAt       *int64
+	failedAt     *int64
+	messageID    *string
+	lastError    *string
+}
+
+// heldUntil reports whether r is leased until the given time.
+func (r *fakeRow) heldUntil(until int64) bool {
+	return r.claimedUntil != nil && *r.claimedUntil == until
+}
+
+func newFakeDB(dialect Dialect) (*fakeDB, *sql.DB) {
+	f := &fakeDB{dialect: dialect, nextID: 1, rows: make(map[int64]*fakeRow)}
+	return f, sql.OpenDB(f)
+}
+
+func (f *fakeDB) row(id int64) fakeRow {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return *f.rows[id]
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct {
+	db *fakeDB
+	// staged holds rows inserted in the open transaction.
+	staged []*fakeRow
+	inTx   bool
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+func (c *fakeConn) Close() error { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.inTx = true
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	for _, r := range c.staged {
+		c.db.rows[r.id] = r
+	}
+	c.staged, c.inTx = nil, false
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	c.staged, c.inTx = nil, false
+	return nil
+}
+
+var placeholders = map[Dialect]*regexp.Regexp{
+	Postgres: regexp.MustCompile(`\$\d+`),
+	SQLite:   regexp.MustCompile(`\?`),
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	rows, err := c.run(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(rows.affected), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.run(query, args)
+}
+
+func (c *fakeConn) run(query string, named []driver.NamedValue) (*fakeRows, error) {
+	f := c.db
+	if n := len(placeholders[f.dialect].FindAllString(query, -1)); n != len(named) {
+		return nil, fmt.Errorf("query has %d placeholders for %d args: %s", n, len(named), query)
+	}
+	args := make([]interface{}, len(named))
+	for i, v := range named {
+		args[i] = v.Value
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failExec != "" && strings.HasPrefix(query, f.failExec) {
+		return nil, errors.New("database unavailable")
+	}
+
+	switch {
+	case strings.HasPrefix(query, "CREATE TABLE"):
+		return &fakeRows{}, nil
+
+	case strings.HasPrefix(query, "INSERT INTO"):
+		r := &fakeRow{id: f.nextID, appID: args[0].(string), payload: args[1].(string), key: args[2].(string), createdAt: args[3].(int64)}
+		f.nextID++
+		if c.inTx {
+			c.staged = append(c.staged, r)
+		} else {
+			f.rows[r.id] = r
+		}
+		return &fakeRows{cols: []string{"id"}, data: [][]driver.Value{{r.id}}}, nil
+
+	case strings.Contains(query, "SET claimed_until") && strings.Contains(query, "attempts = attempts + 1"):
+		until, now, limit := args[0].(int64), args[1].(int64), args[2].(int64)
+		var due []*fakeRow
+		for _, r := range f.rows {
+			if r.sentAt == nil && r.failedAt == nil && (r.claimedUntil == nil || *r.claimedUntil <= now) {
+				due = append(due, r)
+			}
+		}
+		sort.Slice(due, func(i, j int) bool { return due[i].id < due[j].id })
+		if int64(len(due)) > limit {
+			due = due[:limit]
+		}
+		out := &fakeRows{cols: []string{"id", "app_id", "payload", "idempotency_key", "attempts"}}
+		for _, r := range due {
+			r.claimedUntil = &until
+			r.attempts++
+			out.data = append(out.data, []driver.Value{r.id, r.appID, r.payload, r.key, r.attempts})
+		}
+		return out, nil
+
+	case strings.Contains(query, "SET claimed_until") && !strings.Contains(query, "last_error"):
+		until, id, held := args[0].(int64), args[1].(int64), args[2].(int64)
+		r := f.rows[id]
+		if r.sentAt != nil || r.failedAt != nil || !r.heldUntil(held) {
+			return &fakeRows{}, nil
+		}
+		r.claimedUntil = &until
+		return &fakeRows{affected: 1}, nil
+
+	case strings.Contains(query, "SET sent_at"):
+		r := f.rows[args[2].(int64)]
+		if !r.heldUntil(args[3].(int64)) {
+			return &fakeRows{}, nil
+		}
+		at, id := args[0].(int64), args[1].(string)
+		r.sentAt, r.messageID, r.claimedUntil, r.lastError = &at, &id, nil, nil
+		return &fakeRows{affected: 1}, nil
+
+	case strings.Contains(query, "SET claimed_until") && strings.Contains(query, "last_error"):
+		r := f.rows[args[2].(int64)]
+		if !r.heldUntil(args[3].(int64)) {
+			return &fakeRows{}, nil
+		}
+		until, msg := args[0].(int64), args[1].(string)
+		r.claimedUntil, r.lastError = &until, &msg
+		return &fakeRows{affected: 1}, nil
+
+	case strings.Contains(query, "SET failed_at"):
+		r := f.rows[args[2].(int64)]
+		if !r.heldUntil(args[3].(int64)) {
+			return &fakeRows{}, nil
+		}
+		at, msg := args[0].(int64), args[1].(string)
+		r.failedAt, r.claimedUntil, r.lastError = &at, nil, &msg
+		return &fakeRows{affected: 1}, nil
+
+	case strings.HasPrefix(query, "SELECT COUNT(*)"):
+		var n int64
+		for _, r := range f.rows {
+			if r.sentAt == nil && r.failedAt == nil {
+				n++
+			}
+		}
+		return &fakeRows{cols: []string{"count"}, data: [][]driver.Value{{n}}}, nil
+	}
+	return nil, fmt.Errorf("unexpected query: %s", query)
+}
+
+type fakeRows struct {
+	cols []string
+	data [][]driver.Value
+	// affected is the number of rows a statement changed, where it matters.
+	affected int64
+}
+
+func (r *fakeRows) Columns() []string { return r.cols }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.data) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.data[0])
+	r.data = r.data[1:]
+	return nil
+}
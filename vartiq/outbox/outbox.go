@@ -0,0 +1,118 @@
+// Package outbox implements the transactional outbox pattern for Vartiq
+// webhook messages on top of database/sql.
+//
+// Enqueue writes a message into an outbox table inside the caller's
+// transaction, so it is stored if and only if the domain changes commit. A
+// Relay then sends the stored messages with WebhookMessageService.Create and
+// marks them sent. Enqueue gives each row a random idempotency key, which the
+// relay sends with every attempt, so a row sent twice, e.g. after a relay
+// crash, is delivered once.
+//
+//	box, err := outbox.New(db, outbox.Postgres)
+//	...
+//	tx, err := db.BeginTx(ctx, nil)
+//	// ... domain writes on tx ...
+//	if _, err := box.Enqueue(ctx, tx, appID, event); err != nil {
+//	    tx.Rollback()
+//	    return err
+//	}
+//	err = tx.Commit()
+//
+//	// elsewhere, e.g. in a background goroutine:
+//	relay := outbox.NewRelay(box, client.WebhookMessage)
+//	err = relay.Run(ctx)
+//
+// The package does not import a database driver; register the one for your
+// database as usual. Its tests run the generated SQL on SQLite, and on
+// PostgreSQL when VARTIQ_TEST_POSTGRES_DSN is set.
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+)
+
+// DefaultTable is the name of the outbox table unless WithTable is used.
+const DefaultTable = "vartiq_outbox"
+
+// Option configures an Outbox.
+type Option func(*options)
+
+type options struct {
+	table string
+}
+
+// WithTable sets the outbox table name, optionally schema-qualified.
+func WithTable(name string) Option {
+	return func(o *options) {
+		o.table = name
+	}
+}
+
+// tableName matches the table names New accepts; the name is written into
+// the queries, so nothing else is allowed.
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Outbox is an outbox table in a database.
+type Outbox struct {
+	db      *sql.DB
+	table   string
+	queries queries
+	now     func() time.Time
+}
+
+// New returns the outbox for db using dialect. It does not touch the
+// database; call CreateTable to create the table if it does not exist.
+func New(db *sql.DB, dialect Dialect, opts ...Option) (*Outbox, error) {
+	o := options{table: DefaultTable}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if !tableName.MatchString(o.table) {
+		return nil, fmt.Errorf("outbox: invalid table name %q", o.table)
+	}
+	return &Outbox{
+		db:      db,
+		table:   o.table,
+		queries: buildQueries(dialect, o.table),
+		now:     time.Now,
+	}, nil
+}
+
+// CreateTable creates the outbox table if it does not exist.
+func (o *Outbox) CreateTable(ctx context.Context) error {
+	if _, err := o.db.ExecContext(ctx, o.queries.create); err != nil {
+		return fmt.Errorf("outbox: create table %s: %w", o.table, err)
+	}
+	return nil
+}
+
+// Enqueue stores a message for appID in tx and returns its row ID. The
+// message is sent by a Relay once tx commits, and never if it rolls back.
+func (o *Outbox) Enqueue(ctx context.Context, tx *sql.Tx, appID string, payload interface{}) (int64, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("outbox: encode payload: %w", err)
+	}
+	var id int64
+	key := vartiq.NewIdempotencyKey()
+	if err := tx.QueryRowContext(ctx, o.queries.insert, appID, string(raw), key, o.now().UnixMilli()).Scan(&id); err != nil {
+		return 0, fmt.Errorf("outbox: enqueue: %w", err)
+	}
+	return id, nil
+}
+
+// Pending returns the number of messages neither sent nor failed.
+func (o *Outbox) Pending(ctx context.Context) (int, error) {
+	var n int
+	if err := o.db.QueryRowContext(ctx, o.queries.pending).Scan(&n); err != nil {
+		return 0, fmt.Errorf("outbox: count pending: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,95 @@
+package outbox
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_ValidatesTableName(t *testing.T) {
+	_, db := newFakeDB(Postgres)
+	defer db.Close()
+
+	for _, name := range []string{"outbox", "events.outbox", "_Outbox2"} {
+		_, err := New(db, Postgres, WithTable(name))
+		assert.NoError(t, err, name)
+	}
+	for _, name := range []string{"", "1outbox", "outbox; DROP TABLE users", "a.b.c", `"outbox"`} {
+		_, err := New(db, Postgres, WithTable(name))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestQueries_Dialects(t *testing.T) {
+	pg := buildQueries(Postgres, "vartiq_outbox")
+	assert.Contains(t, pg.create, "id BIGSERIAL PRIMARY KEY")
+	assert.Equal(t, "INSERT INTO vartiq_outbox (app_id, payload, idempotency_key, created_at) VALUES ($1, $2, $3, $4) RETURNING id", pg.insert)
+	assert.Contains(t, pg.claim, "LIMIT $3 FOR UPDATE SKIP LOCKED)")
+	assert.Contains(t, pg.claim, "RETURNING id, app_id, payload, idempotency_key, attempts")
+
+	lite := buildQueries(SQLite, "vartiq_outbox")
+	assert.Contains(t, lite.create, "id INTEGER PRIMARY KEY AUTOINCREMENT")
+	assert.Equal(t, "INSERT INTO vartiq_outbox (app_id, payload, idempotency_key, created_at) VALUES (?, ?, ?, ?) RETURNING id", lite.insert)
+	assert.NotContains(t, lite.claim, "FOR UPDATE")
+	assert.False(t, strings.Contains(lite.sent, "$"))
+}
+
+func TestOutbox_EnqueueInTransaction(t *testing.T) {
+	for _, dialect := range []Dialect{Postgres, SQLite} {
+		fake, db := newFakeDB(dialect)
+		box, err := New(db, dialect)
+		require.NoError(t, err)
+		ctx := context.Background()
+		require.NoError(t, box.CreateTable(ctx))
+
+		tx, err := db.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		id, err := box.Enqueue(ctx, tx, "app-1", map[string]string{"type": "invoice.paid"})
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+		assert.Equal(t, `{"type":"invoice.paid"}`, fake.row(id).payload)
+
+		tx, err = db.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		_, err = box.Enqueue(ctx, tx, "app-1", "rolled back")
+		require.NoError(t, err)
+		require.NoError(t, tx.Rollback())
+
+		n, err := box.Pending(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		tx, err = db.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		_, err = box.Enqueue(ctx, tx, "app-1", func() {})
+		assert.ErrorContains(t, err, "encode payload")
+		require.NoError(t, tx.Rollback())
+		db.Close()
+	}
+}
+
+// enqueue stores payloads in committed transactions and returns their IDs.
+func enqueue(t *testing.T, box *Outbox, appID string, payloads ...interface{}) []int64 {
+	t.Helper()
+	ctx := context.Background()
+	var ids []int64
+	for _, payload := range payloads {
+		tx, err := box.db.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		id, err := box.Enqueue(ctx, tx, appID, payload)
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// fixedClock returns a clock for Outbox.now and a function advancing it.
+func fixedClock() (func() time.Time, func(time.Duration)) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	return func() time.Time { return now }, func(d time.Duration) { now = now.Add(d) }
+}
@@ -0,0 +1,301 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+)
+
+// RelayOption configures a Relay.
+type RelayOption func(*relayOptions)
+
+type relayOptions struct {
+	batchSize    int
+	pollInterval time.Duration
+	lease        time.Duration
+	retryBackoff time.Duration
+	maxAttempts  int
+	onError      func(RelayError)
+}
+
+// WithBatchSize sets how many rows a Relay claims at a time. Defaults to 100.
+func WithBatchSize(n int) RelayOption {
+	return func(o *relayOptions) {
+		if n > 0 {
+			o.batchSize = n
+		}
+	}
+}
+
+// WithPollInterval sets how long Run waits before looking for new rows
+// after finding none. Defaults to 1s.
+func WithPollInterval(d time.Duration) RelayOption {
+	return func(o *relayOptions) {
+		if d > 0 {
+			o.pollInterval = d
+		}
+	}
+}
+
+// WithLease sets how long a claimed row is reserved for the Relay that
+// claimed it. If the relay stops before finishing, the row is claimed again
+// once the lease ends. The relay renews each row's lease just before
+// sending it, so the lease must exceed the time one Create call can take,
+// including the client's retries, rather than the time to send a whole
+// batch. Defaults to 1m.
+func WithLease(d time.Duration) RelayOption {
+	return func(o *relayOptions) {
+		if d > 0 {
+			o.lease = d
+		}
+	}
+}
+
+// WithRetryBackoff sets the delay before a row that failed with a retryable
+// error is tried again; it doubles with each attempt, up to one hour.
+// Defaults to 5s.
+func WithRetryBackoff(d time.Duration) RelayOption {
+	return func(o *relayOptions) {
+		if d > 0 {
+			o.retryBackoff = d
+		}
+	}
+}
+
+// WithMaxAttempts sets how many times a row is tried before it is marked
+// failed. Defaults to 10.
+func WithMaxAttempts(n int) RelayOption {
+	return func(o *relayOptions) {
+		if n > 0 {
+			o.maxAttempts = n
+		}
+	}
+}
+
+// WithErrorHandler sets the function called for every failed attempt to
+// send a row, and for database errors in Run.
+func WithErrorHandler(fn func(RelayError)) RelayOption {
+	return func(o *relayOptions) {
+		o.onError = fn
+	}
+}
+
+// RelayError describes a failure while relaying. RowID is zero for
+// database errors not tied to a row.
+type RelayError struct {
+	RowID int64
+	AppID string
+	// Attempts is the number of times the row has been tried.
+	Attempts int
+	// Final reports whether the row was marked failed and will not be
+	// tried again.
+	Final bool
+	Err   error
+}
+
+func (e *RelayError) Error() string {
+	if e.RowID == 0 {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("outbox: row %d: %v", e.RowID, e.Err)
+}
+
+func (e *RelayError) Unwrap() error {
+	return e.Err
+}
+
+// Relay sends the messages stored in an Outbox. Several relays, in one
+// process or many, can work on the same table: each row is claimed by one
+// of them at a time.
+type Relay struct {
+	outbox   *Outbox
+	messages vartiq.WebhookMessageAPI
+	opts     relayOptions
+}
+
+// NewRelay returns a Relay sending the messages in o through messages,
+// usually client.WebhookMessage.
+func NewRelay(o *Outbox, messages vartiq.WebhookMessageAPI, opts ...RelayOption) *Relay {
+	ro := relayOptions{
+		batchSize:    100,
+		pollInterval: time.Second,
+		lease:        time.Minute,
+		retryBackoff: 5 * time.Second,
+		maxAttempts:  10,
+	}
+	for _, opt := range opts {
+		opt(&ro)
+	}
+	return &Relay{outbox: o, messages: messages, opts: ro}
+}
+
+// Run relays messages until ctx ends, then returns ctx's error. Database
+// errors are reported to the error handler and retried after the poll
+// interval.
+func (r *Relay) Run(ctx context.Context) error {
+	for {
+		n, err := r.RelayOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.report(RelayError{Err: err})
+		}
+		if n > 0 && err == nil {
+			continue
+		}
+
+		timer := time.NewTimer(r.opts.pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// row is a claimed outbox row.
+type row struct {
+	id      int64
+	appID   string
+	payload string
+	// key is the idempotency key stored by Enqueue.
+	key      string
+	attempts int
+	// claimedUntil is the end of the relay's lease on the row, in Unix
+	// milliseconds.
+	claimedUntil int64
+}
+
+// RelayOnce claims one batch of due rows and sends them, returning how many
+// rows it claimed. Rows that fail are scheduled for another attempt or
+// marked failed; only database errors are returned.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	rows, err := r.claim(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, rw := range rows {
+		owned, err := r.renew(ctx, &rw)
+		if err != nil {
+			return len(rows), err
+		}
+		if !owned {
+			// The lease ran out and another relay claimed the row.
+			continue
+		}
+		if err := r.send(ctx, rw); err != nil {
+			return len(rows), err
+		}
+	}
+	return len(rows), nil
+}
+
+// renew extends the lease on rw from now, reporting false if the relay no
+// longer holds it.
+func (r *Relay) renew(ctx context.Context, rw *row) (bool, error) {
+	until := r.outbox.now().Add(r.opts.lease).UnixMilli()
+	result, err := r.outbox.db.ExecContext(ctx, r.outbox.queries.renew, until, rw.id, rw.claimedUntil)
+	if err != nil {
+		return false, fmt.Errorf("outbox: renew lease on row %d: %w", rw.id, err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("outbox: renew lease on row %d: %w", rw.id, err)
+	}
+	if n == 0 {
+		return false, nil
+	}
+	rw.claimedUntil = until
+	return true, nil
+}
+
+// claim leases up to batchSize due rows, oldest first.
+func (r *Relay) claim(ctx context.Context) ([]row, error) {
+	now := r.outbox.now()
+	until := now.Add(r.opts.lease).UnixMilli()
+	result, err := r.outbox.db.QueryContext(ctx, r.outbox.queries.claim, until, now.UnixMilli(), r.opts.batchSize)
+	if err != nil {
+		return nil, fmt.Errorf("outbox: claim rows: %w", err)
+	}
+	defer result.Close()
+
+	var rows []row
+	for result.Next() {
+		rw := row{claimedUntil: until}
+		if err := result.Scan(&rw.id, &rw.appID, &rw.payload, &rw.key, &rw.attempts); err != nil {
+			return nil, fmt.Errorf("outbox: claim rows: %w", err)
+		}
+		rows = append(rows, rw)
+	}
+	if err := result.Err(); err != nil {
+		return nil, fmt.Errorf("outbox: claim rows: %w", err)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].id < rows[j].id })
+	return rows, nil
+}
+
+// send creates the message for rw and records the outcome.
+func (r *Relay) send(ctx context.Context, rw row) error {
+	resp, sendErr := r.messages.Create(ctx, rw.appID, json.RawMessage(rw.payload), vartiq.WithIdempotencyKey(rw.key))
+	if sendErr == nil {
+		return r.update(ctx, rw, r.outbox.queries.sent, r.outbox.now().UnixMilli(), resp.Data.ID)
+	}
+	if ctx.Err() != nil {
+		// Shutting down; the lease runs out and the row is claimed again.
+		return nil
+	}
+
+	final := rw.attempts >= r.opts.maxAttempts || !retryable(sendErr)
+	r.report(RelayError{RowID: rw.id, AppID: rw.appID, Attempts: rw.attempts, Final: final, Err: sendErr})
+	if final {
+		return r.update(ctx, rw, r.outbox.queries.failed, r.outbox.now().UnixMilli(), sendErr.Error())
+	}
+	next := r.outbox.now().Add(r.backoff(rw.attempts))
+	return r.update(ctx, rw, r.outbox.queries.retry, next.UnixMilli(), sendErr.Error())
+}
+
+// update runs one of the queries recording the outcome for rw, followed by
+// the row ID and the relay's lease. If the lease ran out and another relay
+// claimed the row, nothing is changed: that relay owns the outcome now, and
+// sends with the same idempotency key.
+func (r *Relay) update(ctx context.Context, rw row, query string, args ...interface{}) error {
+	args = append(args, rw.id, rw.claimedUntil)
+	if _, err := r.outbox.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("outbox: update row %d: %w", rw.id, err)
+	}
+	return nil
+}
+
+// backoff returns the delay after the given number of attempts.
+func (r *Relay) backoff(attempts int) time.Duration {
+	d := r.opts.retryBackoff
+	for i := 1; i < attempts && d < time.Hour; i++ {
+		d *= 2
+	}
+	if d > time.Hour {
+		d = time.Hour
+	}
+	return d
+}
+
+func (r *Relay) report(e RelayError) {
+	if r.opts.onError != nil {
+		r.opts.onError(e)
+	}
+}
+
+// retryable reports whether sending again may succeed: server errors, rate
+// limiting, request timeouts and transient network failures.
+func retryable(err error) bool {
+	var apiErr *vartiq.APIError
+	if errors.As(err, &apiErr) {
+		return errors.Is(err, vartiq.ErrServer) || errors.Is(err, vartiq.ErrRateLimited) ||
+			apiErr.Code == http.StatusRequestTimeout
+	}
+	return vartiq.IsRetryableError(err)
+}
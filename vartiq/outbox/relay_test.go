@@ -0,0 +1,305 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+	"github.com/vartiqhq/vartiq-go-sdk/vartiqmock"
+	"github.com/vartiqhq/vartiq-go-sdk/vartiqtest"
+)
+
+func TestRelay_SendsAndMarksRows(t *testing.T) {
+	fake, db := newFakeDB(Postgres)
+	defer db.Close()
+	box, err := New(db, Postgres)
+	require.NoError(t, err)
+	ids := enqueue(t, box, "app-1", map[string]int{"n": 1}, map[string]int{"n": 2})
+
+	var payloads []string
+	messages := &vartiqmock.WebhookMessageAPIMock{
+		CreateFunc: func(ctx context.Context, appID string, payload interface{}, opts ...vartiq.RequestOption) (*vartiq.Response[vartiq.WebhookMessage], error) {
+			raw, ok := payload.(json.RawMessage)
+			require.True(t, ok)
+			payloads = append(payloads, string(raw))
+			return &vartiq.Response[vartiq.WebhookMessage]{Data: vartiq.WebhookMessage{ID: fmt.Sprintf("msg-%d", len(payloads))}}, nil
+		},
+	}
+
+	relay := NewRelay(box, messages)
+	n, err := relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{`{"n":1}`, `{"n":2}`}, payloads)
+
+	calls := messages.CreateCalls()
+	require.Len(t, calls, 2)
+	assert.Equal(t, "app-1", calls[0].AppID)
+
+	row := fake.row(ids[1])
+	require.NotNil(t, row.sentAt)
+	assert.Equal(t, "msg-2", *row.messageID)
+
+	n, err = relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	pending, err := box.Pending(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, pending)
+}
+
+func TestRelay_UsesStoredIdempotencyKey(t *testing.T) {
+	var keys []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get(vartiq.IdempotencyKeyHeader))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true,"data":{"webhookMessages":[{"id":"msg","payload":"{}"}]}}`))
+	}))
+	defer api.Close()
+	client := vartiq.NewClient("key", vartiq.WithBaseURL(api.URL))
+
+	// Two outboxes with the same table name, e.g. in two services sharing
+	// an API key, must not reuse each other's keys.
+	var want []string
+	for i := 0; i < 2; i++ {
+		fake, db := newFakeDB(Postgres)
+		box, err := New(db, Postgres)
+		require.NoError(t, err)
+		ids := enqueue(t, box, "app-1", "a")
+		want = append(want, fake.row(ids[0]).key)
+
+		_, err = NewRelay(box, client.WebhookMessage).RelayOnce(context.Background())
+		require.NoError(t, err)
+		db.Close()
+	}
+	assert.Equal(t, want, keys)
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, keys[0])
+	assert.NotEqual(t, keys[0], keys[1])
+}
+
+func TestRelay_RetriesAndFails(t *testing.T) {
+	fake, db := newFakeDB(SQLite)
+	defer db.Close()
+	box, err := New(db, SQLite)
+	require.NoError(t, err)
+	now, advance := fixedClock()
+	box.now = now
+	ids := enqueue(t, box, "app-1", "flaky", "invalid")
+
+	messages := &vartiqmock.WebhookMessageAPIMock{
+		CreateFunc: func(ctx context.Context, appID string, payload interface{}, opts ...vartiq.RequestOption) (*vartiq.Response[vartiq.WebhookMessage], error) {
+			if string(payload.(json.RawMessage)) == `"invalid"` {
+				return nil, &vartiq.APIError{Code: 400, Message: "bad payload"}
+			}
+			return nil, &vartiq.APIError{Code: 503, Message: "unavailable"}
+		},
+	}
+	var reported []RelayError
+	relay := NewRelay(box, messages,
+		WithMaxAttempts(2),
+		WithRetryBackoff(time.Minute),
+		WithErrorHandler(func(e RelayError) { reported = append(reported, e) }))
+	ctx := context.Background()
+
+	_, err = relay.RelayOnce(ctx)
+	require.NoError(t, err)
+	require.Len(t, reported, 2)
+	assert.False(t, reported[0].Final)
+	assert.True(t, reported[1].Final, "validation errors are not retried")
+	assert.ErrorIs(t, &reported[1], vartiq.ErrValidation)
+
+	flaky := fake.row(ids[0])
+	assert.Equal(t, now().Add(time.Minute).UnixMilli(), *flaky.claimedUntil)
+	assert.Equal(t, "unavailable", *flaky.lastError)
+	require.NotNil(t, fake.row(ids[1]).failedAt)
+
+	// Not due yet.
+	n, err := relay.RelayOnce(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	advance(time.Minute)
+	n, err = relay.RelayOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	require.Len(t, reported, 3)
+	assert.True(t, reported[2].Final)
+	assert.Equal(t, 2, reported[2].Attempts)
+	require.NotNil(t, fake.row(ids[0]).failedAt)
+}
+
+func TestRelay_LeaseExpiry(t *testing.T) {
+	fake, db := newFakeDB(Postgres)
+	defer db.Close()
+	box, err := New(db, Postgres)
+	require.NoError(t, err)
+	now, advance := fixedClock()
+	box.now = now
+	ids := enqueue(t, box, "app-1", "x")
+
+	// A relay claimed the row and died before finishing.
+	ctx, cancel := context.WithCancel(context.Background())
+	dying := NewRelay(box, &vartiqmock.WebhookMessageAPIMock{
+		CreateFunc: func(context.Context, string, interface{}, ...vartiq.RequestOption) (*vartiq.Response[vartiq.WebhookMessage], error) {
+			cancel()
+			return nil, context.Canceled
+		},
+	}, WithLease(time.Minute))
+	n, err := dying.RelayOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Nil(t, fake.row(ids[0]).sentAt)
+
+	ok := NewRelay(box, &vartiqmock.WebhookMessageAPIMock{
+		CreateFunc: func(context.Context, string, interface{}, ...vartiq.RequestOption) (*vartiq.Response[vartiq.WebhookMessage], error) {
+			return &vartiq.Response[vartiq.WebhookMessage]{Data: vartiq.WebhookMessage{ID: "msg"}}, nil
+		},
+	})
+	n, err = ok.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n, "row is leased")
+
+	advance(time.Minute)
+	n, err = ok.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.EqualValues(t, 2, fake.row(ids[0]).attempts)
+	assert.NotNil(t, fake.row(ids[0]).sentAt)
+}
+
+func TestRelay_RenewsLeaseBeforeEachRow(t *testing.T) {
+	fake, db := newFakeDB(Postgres)
+	defer db.Close()
+	box, err := New(db, Postgres)
+	require.NoError(t, err)
+	now, advance := fixedClock()
+	box.now = now
+	ids := enqueue(t, box, "app-1", "a", "b", "c")
+
+	var sent []string
+	var other *Relay
+	create := func(ctx context.Context, appID string, payload interface{}, opts ...vartiq.RequestOption) (*vartiq.Response[vartiq.WebhookMessage], error) {
+		p := string(payload.(json.RawMessage))
+		sent = append(sent, p)
+		switch p {
+		case `"a"`:
+			advance(50 * time.Second)
+		case `"b"`:
+			// The batch's first lease has run out while b is being sent.
+			advance(50 * time.Second)
+			n, err := other.RelayOnce(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, 1, n, "only c, whose lease was not renewed yet, is claimed")
+		}
+		return &vartiq.Response[vartiq.WebhookMessage]{Data: vartiq.WebhookMessage{ID: "msg-" + p}}, nil
+	}
+	messages := &vartiqmock.WebhookMessageAPIMock{CreateFunc: create}
+	other = NewRelay(box, messages, WithLease(time.Minute))
+
+	n, err := NewRelay(box, messages, WithLease(time.Minute)).RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	assert.Equal(t, []string{`"a"`, `"b"`, `"c"`}, sent, "every row is sent once")
+	for _, id := range ids {
+		assert.NotNil(t, fake.row(id).sentAt)
+	}
+	assert.EqualValues(t, 1, fake.row(ids[1]).attempts, "b was not claimed again while being sent")
+	assert.EqualValues(t, 2, fake.row(ids[2]).attempts)
+}
+
+func TestRelay_LateOutcomeAfterLostLease(t *testing.T) {
+	fake, db := newFakeDB(SQLite)
+	defer db.Close()
+	box, err := New(db, SQLite)
+	require.NoError(t, err)
+	now, advance := fixedClock()
+	box.now = now
+	ids := enqueue(t, box, "app-1", "x")
+
+	other := NewRelay(box, &vartiqmock.WebhookMessageAPIMock{
+		CreateFunc: func(context.Context, string, interface{}, ...vartiq.RequestOption) (*vartiq.Response[vartiq.WebhookMessage], error) {
+			return &vartiq.Response[vartiq.WebhookMessage]{Data: vartiq.WebhookMessage{ID: "msg-other"}}, nil
+		},
+	}, WithLease(time.Minute))
+	slow := NewRelay(box, &vartiqmock.WebhookMessageAPIMock{
+		CreateFunc: func(ctx context.Context, _ string, _ interface{}, _ ...vartiq.RequestOption) (*vartiq.Response[vartiq.WebhookMessage], error) {
+			// The lease runs out mid-send and another relay sends the row.
+			advance(2 * time.Minute)
+			n, err := other.RelayOnce(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, 1, n)
+			return nil, &vartiq.APIError{Code: 400, Message: "bad payload"}
+		},
+	}, WithLease(time.Minute))
+
+	n, err := slow.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	row := fake.row(ids[0])
+	assert.Nil(t, row.failedAt, "the late failure is not recorded")
+	require.NotNil(t, row.sentAt)
+	assert.Equal(t, "msg-other", *row.messageID)
+	assert.Nil(t, row.lastError)
+}
+
+func TestRelay_RunReportsDatabaseErrors(t *testing.T) {
+	fake, db := newFakeDB(Postgres)
+	defer db.Close()
+	box, err := New(db, Postgres)
+	require.NoError(t, err)
+	fake.failExec = "UPDATE"
+
+	errs := make(chan RelayError, 1)
+	relay := NewRelay(box, &vartiqmock.WebhookMessageAPIMock{},
+		WithPollInterval(time.Millisecond),
+		WithErrorHandler(func(e RelayError) {
+			select {
+			case errs <- e:
+			default:
+			}
+		}))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- relay.Run(ctx) }()
+
+	e := <-errs
+	assert.Zero(t, e.RowID)
+	assert.ErrorContains(t, &e, "database unavailable")
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestRelay_EndToEnd(t *testing.T) {
+	srv := vartiqtest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+	project, err := client.Project.Create(ctx, &vartiq.CreateProjectRequest{Name: "Project"})
+	require.NoError(t, err)
+	app, err := client.App.Create(ctx, &vartiq.CreateAppRequest{Name: "App", ProjectID: project.Data.ID})
+	require.NoError(t, err)
+
+	_, db := newFakeDB(Postgres)
+	defer db.Close()
+	box, err := New(db, Postgres)
+	require.NoError(t, err)
+	enqueue(t, box, app.Data.ID, map[string]string{"type": "user.created"})
+
+	n, err := NewRelay(box, client.WebhookMessage).RelayOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	sent, err := client.WebhookMessage.List(ctx, app.Data.ID, nil)
+	require.NoError(t, err)
+	require.Len(t, sent.Data, 1)
+	assert.Equal(t, map[string]interface{}{"type": "user.created"}, sent.Data[0].Payload)
+}
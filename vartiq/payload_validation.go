@@ -0,0 +1,138 @@
+package vartiq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq/schema"
+)
+
+// DefaultSchemaCacheTTL is how long WithSchemaValidation keeps an app's
+// schemas unless WithSchemaCacheTTL is used.
+const DefaultSchemaCacheTTL = 5 * time.Minute
+
+// WithSchemaValidation makes WebhookMessage.Create and CreateBatch check
+// payloads sent with WithEventType against the JSON Schema of that event
+// type before sending them. A payload that does not conform is rejected
+// with a *ValidationError listing a JSON pointer to each violation.
+//
+// Schemas are fetched with EventType.List the first time an app's event
+// type is used, and cached for DefaultSchemaCacheTTL, including the absence
+// of event types unknown to the app. Event types without a schema, or
+// unknown to the app, are not checked. EventType.Create through the same
+// client, and InvalidateSchemas, drop an app's cached schemas at once.
+func WithSchemaValidation() Option {
+	return func(o *clientOptions) {
+		o.validateSchemas = true
+	}
+}
+
+// WithSchemaCacheTTL sets how long WithSchemaValidation keeps an app's
+// schemas before fetching them again. Defaults to DefaultSchemaCacheTTL.
+func WithSchemaCacheTTL(ttl time.Duration) Option {
+	return func(o *clientOptions) {
+		if ttl > 0 {
+			o.schemaCacheTTL = ttl
+		}
+	}
+}
+
+// schemaCache holds the compiled schemas of each app's event types.
+type schemaCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu   sync.Mutex
+	apps map[string]appSchemas
+}
+
+// appSchemas are the schemas of one app's event types as listed at
+// loadedAt. Event types without a schema map to nil; event types missing
+// from the map were unknown to the app.
+type appSchemas struct {
+	schemas  map[string]*schema.Schema
+	loadedAt time.Time
+}
+
+func newSchemaCache(ttl time.Duration) *schemaCache {
+	if ttl <= 0 {
+		ttl = DefaultSchemaCacheTTL
+	}
+	return &schemaCache{ttl: ttl, now: time.Now, apps: make(map[string]appSchemas)}
+}
+
+// InvalidateSchemas drops the cached event type schemas of appID, so the
+// next message sent with an event type fetches them again. It does nothing
+// unless the client was created with WithSchemaValidation.
+func (c *Client) InvalidateSchemas(appID string) {
+	if c.schemas == nil {
+		return
+	}
+	c.schemas.mu.Lock()
+	delete(c.schemas.apps, appID)
+	c.schemas.mu.Unlock()
+}
+
+// payloadSchema returns the schema payloads of eventType must match, or nil
+// when they are not checked.
+func (c *Client) payloadSchema(ctx context.Context, appID, eventType string) (*schema.Schema, error) {
+	if c.schemas == nil || eventType == "" {
+		return nil, nil
+	}
+	c.schemas.mu.Lock()
+	cached, ok := c.schemas.apps[appID]
+	c.schemas.mu.Unlock()
+	if ok && c.schemas.now().Sub(cached.loadedAt) < c.schemas.ttl {
+		return cached.schemas[eventType], nil
+	}
+
+	loadedAt := c.schemas.now()
+	resp, err := c.EventType.List(ctx, appID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load event type schemas: %w", err)
+	}
+	compiled := make(map[string]*schema.Schema, len(resp.Data))
+	for _, et := range resp.Data {
+		if !hasSchema(et.Schema) {
+			compiled[et.Name] = nil
+			continue
+		}
+		if compiled[et.Name], err = schema.Compile(et.Schema); err != nil {
+			return nil, fmt.Errorf("event type %s has an invalid schema: %w", et.Name, err)
+		}
+	}
+
+	c.schemas.mu.Lock()
+	defer c.schemas.mu.Unlock()
+	c.schemas.apps[appID] = appSchemas{schemas: compiled, loadedAt: loadedAt}
+	return compiled[eventType], nil
+}
+
+// validatePayload checks payload against s, converting violations into a
+// *ValidationError whose fields are JSON pointers into the payload.
+func validatePayload(s *schema.Schema, eventType string, payload interface{}) error {
+	err := s.Validate(payload)
+	var verr *schema.ValidationError
+	if !errors.As(err, &verr) {
+		return err
+	}
+	fields := make([]FieldError, len(verr.Violations))
+	msgs := make([]string, len(verr.Violations))
+	for i, v := range verr.Violations {
+		fields[i] = FieldError{Field: v.InstanceLocation, Message: v.Message}
+		msgs[i] = v.String()
+	}
+	return &ValidationError{
+		Message: fmt.Sprintf("payload does not match the schema of event type %s: %s", eventType, strings.Join(msgs, "; ")),
+		Fields:  fields,
+	}
+}
+
+// hasSchema reports whether raw holds a schema rather than nothing or null.
+func hasSchema(raw []byte) bool {
+	return len(raw) > 0 && string(raw) != "null"
+}
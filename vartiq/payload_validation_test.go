@@ -0,0 +1,187 @@
+package vartiq
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const invoicePaidSchema = `{
+	"type": "object",
+	"required": ["invoiceId", "amount"],
+	"properties": {
+		"invoiceId": {"type": "string"},
+		"amount": {"type": "integer", "minimum": 0}
+	}
+}`
+
+// newSchemaServer serves an app with a schema-bearing event type and an
+// event type without schema, counting list and create requests.
+func newSchemaServer(t *testing.T, lists, creates *int32) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/event-types":
+			if r.Method == http.MethodPost {
+				_, _ = w.Write([]byte(`{"success":true,"data":{"id":"et-3","name":"invoice.voided"}}`))
+				return
+			}
+			atomic.AddInt32(lists, 1)
+			body, _ := json.Marshal(map[string]interface{}{
+				"success": true,
+				"data": []map[string]interface{}{
+					{"id": "et-1", "name": "invoice.paid", "schema": json.RawMessage(invoicePaidSchema)},
+					{"id": "et-2", "name": "user.deleted"},
+				},
+			})
+			_, _ = w.Write(body)
+		case "/webhook-messages":
+			atomic.AddInt32(creates, 1)
+			_, _ = w.Write([]byte(`{"success":true,"data":{"webhookMessages":[{"id":"m1","payload":"{}"}]}}`))
+		case "/webhook-messages/batch":
+			var req struct {
+				Messages []json.RawMessage `json:"messages"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			atomic.AddInt32(creates, int32(len(req.Messages)))
+			results := make([]map[string]interface{}, len(req.Messages))
+			for i := range results {
+				results[i] = map[string]interface{}{"webhookMessages": []map[string]string{{"id": "m", "payload": "{}"}}}
+			}
+			body, _ := json.Marshal(map[string]interface{}{"success": true, "data": map[string]interface{}{"results": results}})
+			_, _ = w.Write(body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"success":false,"message":"Route not found"}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestWithSchemaValidation_Create(t *testing.T) {
+	var lists, creates int32
+	srv := newSchemaServer(t, &lists, &creates)
+	client := NewClient("test-key", WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{}), WithSchemaValidation())
+	ctx := context.Background()
+
+	_, err := client.WebhookMessage.Create(ctx, "app-1", map[string]interface{}{"invoiceId": "inv_1", "amount": 5}, WithEventType("invoice.paid"))
+	require.NoError(t, err)
+
+	_, err = client.WebhookMessage.Create(ctx, "app-1", map[string]interface{}{"amount": -5}, WithEventType("invoice.paid"))
+	assert.ErrorIs(t, err, ErrValidation)
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, []FieldError{
+		{Field: "/invoiceId", Message: "is required"},
+		{Field: "/amount", Message: "must be >= 0"},
+	}, verr.Fields)
+	assert.EqualError(t, err, "payload does not match the schema of event type invoice.paid: /invoiceId: is required; /amount: must be >= 0")
+
+	// No schema, unknown event types and untyped messages are not checked.
+	_, err = client.WebhookMessage.Create(ctx, "app-1", "anything", WithEventType("user.deleted"))
+	require.NoError(t, err)
+	_, err = client.WebhookMessage.Create(ctx, "app-1", "anything")
+	require.NoError(t, err)
+
+	assert.EqualValues(t, 1, atomic.LoadInt32(&lists), "schemas are cached per app")
+	assert.EqualValues(t, 3, atomic.LoadInt32(&creates))
+}
+
+func TestWithSchemaValidation_CreateBatch(t *testing.T) {
+	var lists, creates int32
+	srv := newSchemaServer(t, &lists, &creates)
+	client := NewClient("test-key", WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{}), WithSchemaValidation())
+
+	results, err := client.WebhookMessage.CreateBatch(context.Background(), "app-1", []interface{}{
+		map[string]interface{}{"invoiceId": "inv_1", "amount": 1},
+		map[string]interface{}{"invoiceId": 2, "amount": 1},
+		map[string]interface{}{"invoiceId": "inv_3", "amount": 3},
+	}, WithEventType("invoice.paid"))
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.NoError(t, results[0].Err)
+	assert.ErrorIs(t, results[1].Err, ErrValidation)
+	assert.ErrorContains(t, results[1].Err, "/invoiceId: must be of type string, got integer")
+	assert.NoError(t, results[2].Err)
+	for i, r := range results {
+		assert.Equal(t, i, r.Index)
+	}
+	assert.EqualValues(t, 2, atomic.LoadInt32(&creates), "invalid payloads are not sent")
+}
+
+func TestWithSchemaValidation_Cache(t *testing.T) {
+	var lists, creates int32
+	srv := newSchemaServer(t, &lists, &creates)
+	client := NewClient("test-key", WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{}),
+		WithSchemaValidation(), WithSchemaCacheTTL(time.Minute))
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	client.schemas.now = func() time.Time { return now }
+	ctx := context.Background()
+	send := func(eventType string) {
+		t.Helper()
+		_, err := client.WebhookMessage.Create(ctx, "app-1", "x", WithEventType(eventType))
+		require.NoError(t, err)
+	}
+
+	// Unknown event types are cached as such rather than listed again.
+	send("invoice.voided")
+	send("invoice.voided")
+	send("user.deleted")
+	assert.EqualValues(t, 1, atomic.LoadInt32(&lists))
+
+	now = now.Add(59 * time.Second)
+	send("invoice.voided")
+	assert.EqualValues(t, 1, atomic.LoadInt32(&lists))
+	now = now.Add(time.Second)
+	send("invoice.voided")
+	assert.EqualValues(t, 2, atomic.LoadInt32(&lists), "schemas are fetched again after the TTL")
+
+	client.InvalidateSchemas("app-1")
+	send("invoice.voided")
+	assert.EqualValues(t, 3, atomic.LoadInt32(&lists))
+
+	_, err := client.EventType.Create(ctx, &CreateEventTypeRequest{AppID: "app-1", Name: "invoice.voided"})
+	require.NoError(t, err)
+	send("invoice.voided")
+	assert.EqualValues(t, 4, atomic.LoadInt32(&lists), "creating an event type drops the app's schemas")
+
+	// Other apps and clients without validation are unaffected.
+	client.InvalidateSchemas("app-2")
+	NewClient("test-key").InvalidateSchemas("app-1")
+	send("invoice.voided")
+	assert.EqualValues(t, 4, atomic.LoadInt32(&lists))
+}
+
+func TestWithSchemaValidation_LoadErrors(t *testing.T) {
+	var lists, creates int32
+	srv := newSchemaServer(t, &lists, &creates)
+	client := NewClient("test-key", WithBaseURL(srv.URL+"/missing"), WithRetryPolicy(RetryPolicy{}), WithSchemaValidation())
+
+	_, err := client.WebhookMessage.Create(context.Background(), "app-1", "x", WithEventType("invoice.paid"))
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorContains(t, err, "failed to load event type schemas")
+
+	results, err := client.WebhookMessage.CreateBatch(context.Background(), "app-1", []interface{}{"x"}, WithEventType("invoice.paid"))
+	require.NoError(t, err)
+	assert.ErrorIs(t, results[0].Err, ErrNotFound)
+	assert.Zero(t, atomic.LoadInt32(&creates))
+}
+
+func TestCreateEventTypeRequest_ValidatesSchema(t *testing.T) {
+	client := NewClient("test-key", WithBaseURL("http://127.0.0.1:0"))
+	_, err := client.EventType.Create(context.Background(), &CreateEventTypeRequest{
+		AppID: "app-1", Name: "invoice.paid", Schema: json.RawMessage(`{"type": "money"}`),
+	})
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, "schema", verr.Fields[0].Field)
+	assert.Contains(t, verr.Fields[0].Message, "unknown type money")
+}
@@ -0,0 +1,496 @@
+package vartiq
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrProducerClosed is returned by Producer.Send after Close was called.
+	ErrProducerClosed = errors.New("vartiq: producer closed")
+	// ErrQueueFull is returned by Producer.TrySend when the queue has no room.
+	ErrQueueFull = errors.New("vartiq: producer queue full")
+)
+
+// ProducerFailure describes a message the Producer gave up on.
+type ProducerFailure struct {
+	AppID   string
+	Payload interface{}
+	// Attempts is the number of times the message was sent.
+	Attempts int
+	Err      error
+}
+
+// ProducerOption configures a Producer.
+type ProducerOption func(*producerOptions)
+
+type producerOptions struct {
+	queueSize     int
+	workers       int
+	batchSize     int
+	flushInterval time.Duration
+	maxAttempts   int
+	retryBackoff  time.Duration
+	onFailure     func(ProducerFailure)
+	wal           *WAL
+}
+
+// WithQueueSize sets how many messages the Producer holds before Send
+// blocks. Defaults to 10000.
+func WithQueueSize(n int) ProducerOption {
+	return func(o *producerOptions) {
+		if n > 0 {
+			o.queueSize = n
+		}
+	}
+}
+
+// WithWorkers sets how many batches are sent concurrently. Defaults to 4.
+func WithWorkers(n int) ProducerOption {
+	return func(o *producerOptions) {
+		if n > 0 {
+			o.workers = n
+		}
+	}
+}
+
+// WithBatchSize sets how many messages for the same app are sent in one
+// request. It is capped at MaxBatchSize, which is also the default.
+func WithBatchSize(n int) ProducerOption {
+	return func(o *producerOptions) {
+		if n > 0 && n <= MaxBatchSize {
+			o.batchSize = n
+		}
+	}
+}
+
+// WithFlushInterval sets how long a message may wait for its batch to fill
+// before it is sent anyway. Defaults to 1s.
+func WithFlushInterval(d time.Duration) ProducerOption {
+	return func(o *producerOptions) {
+		if d > 0 {
+			o.flushInterval = d
+		}
+	}
+}
+
+// WithMaxAttempts sets how many times the Producer sends a message that
+// fails with a retryable error before reporting it. These attempts come on
+// top of the client's own RetryPolicy. Defaults to 5.
+func WithMaxAttempts(n int) ProducerOption {
+	return func(o *producerOptions) {
+		if n > 0 {
+			o.maxAttempts = n
+		}
+	}
+}
+
+// WithRetryBackoff sets the delay before the Producer's first resend; it
+// doubles for each further attempt. Defaults to 1s.
+func WithRetryBackoff(d time.Duration) ProducerOption {
+	return func(o *producerOptions) {
+		if d > 0 {
+			o.retryBackoff = d
+		}
+	}
+}
+
+// WithFailureHandler sets the function called for each message the
+// Producer gives up on. It is called from the Producer's workers, possibly
+// concurrently, and should not block for long.
+func WithFailureHandler(fn func(ProducerFailure)) ProducerOption {
+	return func(o *producerOptions) {
+		o.onFailure = fn
+	}
+}
+
+// WithWAL makes the Producer durable: every message is recorded in w
+// before Send returns, and NewProducer queues the messages w holds from an
+// earlier run. Messages that Close aborts stay in w, without being reported
+// as failed, and are sent on the next start. The Producer closes w when it
+// is closed. Messages read back from w carry their payload as
+// json.RawMessage.
+func WithWAL(w *WAL) ProducerOption {
+	return func(o *producerOptions) {
+		o.wal = w
+	}
+}
+
+// producerItem is a message waiting to be sent.
+type producerItem struct {
+	appID   string
+	payload interface{}
+	// walID identifies the message in the WAL, if there is one.
+	walID uint64
+}
+
+// producerBatch is a set of messages for one app, sent in one request.
+type producerBatch struct {
+	appID string
+	items []*producerItem
+}
+
+// Producer sends webhook messages in the background. Send queues a message
+// and returns at once; queued messages are grouped per app and sent with
+// CreateBatch by a pool of workers, retrying transient failures. Messages
+// that still cannot be sent are reported to the failure handler.
+//
+// Call Close to send the remaining messages before the program exits.
+type Producer struct {
+	messages WebhookMessageAPI
+	opts     producerOptions
+
+	queue chan *producerItem
+	work  chan producerBatch
+	flush chan struct{}
+	done  chan struct{}
+
+	// ctx bounds the requests; it is canceled when Close gives up waiting.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// closeMu guards closed and the sends on queue against Close.
+	closeMu sync.RWMutex
+	closed  bool
+	// closing is closed when Close is called, before it takes closeMu, so
+	// that Sends waiting for room in the queue give up and release it.
+	closing   chan struct{}
+	closeOnce sync.Once
+
+	mu      sync.Mutex
+	pending int
+	idle    []chan struct{}
+}
+
+// NewProducer starts a Producer sending through messages, usually
+// client.WebhookMessage.
+// Example:
+//
+//	producer := vartiq.NewProducer(client.WebhookMessage,
+//	    vartiq.WithFailureHandler(func(f vartiq.ProducerFailure) {
+//	        log.Printf("dropped message for %s: %v", f.AppID, f.Err)
+//	    }))
+//	defer producer.Close(context.Background())
+func NewProducer(messages WebhookMessageAPI, opts ...ProducerOption) *Producer {
+	o := producerOptions{
+		queueSize:     10000,
+		workers:       4,
+		batchSize:     MaxBatchSize,
+		flushInterval: time.Second,
+		maxAttempts:   5,
+		retryBackoff:  time.Second,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Producer{
+		messages: messages,
+		opts:     o,
+		queue:    make(chan *producerItem, o.queueSize),
+		work:     make(chan producerBatch),
+		flush:    make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		closing:  make(chan struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+
+	var workers sync.WaitGroup
+	for i := 0; i < o.workers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for b := range p.work {
+				p.send(b)
+			}
+		}()
+	}
+	go p.dispatch()
+	go func() {
+		workers.Wait()
+		close(p.done)
+	}()
+
+	if o.wal != nil {
+		for _, rec := range o.wal.unsent() {
+			p.track(1)
+			p.queue <- &producerItem{appID: rec.AppID, payload: rec.Payload, walID: rec.ID}
+		}
+	}
+	return p
+}
+
+// Send queues a message for appID, waiting for room in the queue until ctx
+// ends. It returns ErrProducerClosed once Close has been called, including
+// when Close is called while it waits.
+func (p *Producer) Send(ctx context.Context, appID string, payload interface{}) error {
+	p.closeMu.RLock()
+	defer p.closeMu.RUnlock()
+	if p.closed {
+		return ErrProducerClosed
+	}
+
+	item, err := p.accept(appID, payload)
+	if err != nil {
+		return err
+	}
+	select {
+	case p.queue <- item:
+		return nil
+	case <-ctx.Done():
+		p.finish(item)
+		return ctx.Err()
+	case <-p.closing:
+		p.finish(item)
+		return ErrProducerClosed
+	}
+}
+
+// TrySend queues a message for appID like Send, but returns ErrQueueFull
+// instead of waiting when the queue has no room.
+func (p *Producer) TrySend(appID string, payload interface{}) error {
+	p.closeMu.RLock()
+	defer p.closeMu.RUnlock()
+	if p.closed {
+		return ErrProducerClosed
+	}
+
+	item, err := p.accept(appID, payload)
+	if err != nil {
+		return err
+	}
+	select {
+	case p.queue <- item:
+		return nil
+	default:
+		p.finish(item)
+		return ErrQueueFull
+	}
+}
+
+// accept counts a new message as pending and records it in the WAL.
+func (p *Producer) accept(appID string, payload interface{}) (*producerItem, error) {
+	item := &producerItem{appID: appID, payload: payload}
+	if p.opts.wal != nil {
+		id, err := p.opts.wal.add(appID, payload)
+		if err != nil {
+			return nil, err
+		}
+		item.walID = id
+	}
+	p.track(1)
+	return item, nil
+}
+
+// finish marks a message as no longer pending and removes it from the WAL.
+func (p *Producer) finish(item *producerItem) {
+	if p.opts.wal != nil {
+		_ = p.opts.wal.done(item.walID)
+	}
+	p.track(-1)
+}
+
+// Flush sends the queued messages without waiting for their batches to
+// fill, and waits until every queued message, including any queued in the
+// meantime, has been sent or reported as failed, or until ctx ends.
+func (p *Producer) Flush(ctx context.Context) error {
+	p.mu.Lock()
+	if p.pending == 0 {
+		p.mu.Unlock()
+		return nil
+	}
+	idle := make(chan struct{})
+	p.idle = append(p.idle, idle)
+	p.mu.Unlock()
+
+	select {
+	case p.flush <- struct{}{}:
+	default:
+	}
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting messages, sends those still queued and waits for
+// the workers to finish. If ctx ends first, Close aborts the requests in
+// flight, reports the unsent messages to the failure handler and returns
+// ctx's error.
+func (p *Producer) Close(ctx context.Context) error {
+	p.closeOnce.Do(func() { close(p.closing) })
+	p.closeMu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.queue)
+	}
+	p.closeMu.Unlock()
+
+	var err error
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		p.cancel()
+		<-p.done
+		err = ctx.Err()
+	}
+	p.cancel()
+	if p.opts.wal != nil {
+		if walErr := p.opts.wal.Close(); err == nil {
+			err = walErr
+		}
+	}
+	return err
+}
+
+// track adjusts the number of messages not yet sent or reported, waking
+// Flush callers when it drops to zero.
+func (p *Producer) track(delta int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pending += delta
+	if p.pending == 0 {
+		for _, idle := range p.idle {
+			close(idle)
+		}
+		p.idle = nil
+	}
+}
+
+// dispatch groups queued messages into per-app batches and hands them to
+// the workers when full, on every flush interval, and on Flush.
+func (p *Producer) dispatch() {
+	ticker := time.NewTicker(p.opts.flushInterval)
+	defer ticker.Stop()
+
+	batches := make(map[string][]*producerItem)
+	flushAll := func() {
+		for appID, items := range batches {
+			p.work <- producerBatch{appID: appID, items: items}
+			delete(batches, appID)
+		}
+	}
+	add := func(item *producerItem) {
+		batches[item.appID] = append(batches[item.appID], item)
+		if items := batches[item.appID]; len(items) >= p.opts.batchSize {
+			p.work <- producerBatch{appID: item.appID, items: items}
+			delete(batches, item.appID)
+		}
+	}
+
+	for {
+		select {
+		case item, ok := <-p.queue:
+			if !ok {
+				flushAll()
+				close(p.work)
+				return
+			}
+			add(item)
+		case <-ticker.C:
+			flushAll()
+		case <-p.flush:
+			for drained := false; !drained; {
+				select {
+				case item, ok := <-p.queue:
+					if ok {
+						add(item)
+					} else {
+						drained = true
+					}
+				default:
+					drained = true
+				}
+			}
+			flushAll()
+		}
+	}
+}
+
+// send delivers a batch, resending the messages that fail with a retryable
+// error. When the batch request itself failed, the resend reuses its
+// idempotency key so the API can drop duplicates. When the API answered
+// with errors for some or all of the messages, it has stored that answer
+// under the key, so the resend needs a new one.
+func (p *Producer) send(b producerBatch) {
+	items := b.items
+	key := NewIdempotencyKey()
+	backoff := p.opts.retryBackoff
+	for attempt := 1; ; attempt++ {
+		payloads := make([]interface{}, len(items))
+		for i, item := range items {
+			payloads[i] = item.payload
+		}
+		results, err := p.messages.CreateBatch(p.ctx, b.appID, payloads, WithIdempotencyKey(key))
+		if err != nil && len(results) != len(items) {
+			results = make([]BatchResult, len(items))
+			for i := range results {
+				results[i] = BatchResult{Index: i, Err: err, RequestFailed: true}
+			}
+		}
+
+		var retry []*producerItem
+		for i, r := range results {
+			switch {
+			case r.Err == nil:
+				p.finish(items[i])
+			case attempt < p.opts.maxAttempts && p.ctx.Err() == nil && isRetryableSendError(r.Err):
+				retry = append(retry, items[i])
+			default:
+				p.fail(b.appID, items[i], attempt, r.Err)
+			}
+		}
+		if len(retry) == 0 {
+			return
+		}
+		if len(retry) != len(items) || !results[0].RequestFailed {
+			key = NewIdempotencyKey()
+		}
+		items = retry
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-p.ctx.Done():
+			timer.Stop()
+			for _, item := range items {
+				p.fail(b.appID, item, attempt, p.ctx.Err())
+			}
+			return
+		}
+		backoff *= 2
+	}
+}
+
+// fail reports a message the Producer gives up on. With a WAL, a message
+// aborted by Close is kept for the next start instead.
+func (p *Producer) fail(appID string, item *producerItem, attempts int, err error) {
+	if p.opts.wal != nil && p.ctx.Err() != nil {
+		p.track(-1)
+		return
+	}
+	if p.opts.onFailure != nil {
+		p.opts.onFailure(ProducerFailure{AppID: appID, Payload: item.payload, Attempts: attempts, Err: err})
+	}
+	p.finish(item)
+}
+
+// isRetryableSendError reports whether sending a message again may succeed:
+// server errors, rate limiting, request timeouts and transient network
+// failures.
+func isRetryableSendError(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return errors.Is(err, ErrServer) || errors.Is(err, ErrRateLimited) ||
+			apiErr.Code == http.StatusRequestTimeout
+	}
+	return IsRetryableError(err)
+}
@@ -0,0 +1,271 @@
+package vartiq
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchRecorder is a WebhookMessageAPI whose CreateBatch records its calls
+// and answers with fn, or succeeds when fn is nil. When requestErr returns
+// an error, the whole request fails with it instead.
+type batchRecorder struct {
+	WebhookMessageAPI
+
+	mu         sync.Mutex
+	calls      []batchCall
+	fn         func(call int, payloads []interface{}) []error
+	requestErr func(call int) error
+}
+
+type batchCall struct {
+	appID    string
+	payloads []interface{}
+	key      string
+}
+
+func (r *batchRecorder) CreateBatch(ctx context.Context, appID string, payloads []interface{}, opts ...RequestOption) ([]BatchResult, error) {
+	o := &requestOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	r.mu.Lock()
+	r.calls = append(r.calls, batchCall{appID: appID, payloads: payloads, key: o.idempotencyKey})
+	call := len(r.calls)
+	r.mu.Unlock()
+
+	results := make([]BatchResult, len(payloads))
+	if r.requestErr != nil {
+		if err := r.requestErr(call); err != nil {
+			for i := range results {
+				results[i] = BatchResult{Index: i, Err: err, RequestFailed: true}
+			}
+			return results, ctx.Err()
+		}
+	}
+	var errs []error
+	if r.fn != nil {
+		errs = r.fn(call, payloads)
+	}
+	for i := range results {
+		results[i].Index = i
+		if errs != nil {
+			results[i].Err = errs[i]
+		}
+		if results[i].Err == nil && ctx.Err() != nil {
+			results[i].Err = ctx.Err()
+		}
+	}
+	return results, ctx.Err()
+}
+
+func (r *batchRecorder) sent() []batchCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]batchCall(nil), r.calls...)
+}
+
+func TestProducer_BatchesPerApp(t *testing.T) {
+	rec := &batchRecorder{}
+	p := NewProducer(rec, WithBatchSize(2), WithFlushInterval(time.Hour), WithWorkers(1))
+	ctx := context.Background()
+
+	require.NoError(t, p.Send(ctx, "app-1", 1))
+	require.NoError(t, p.Send(ctx, "app-2", 2))
+	require.NoError(t, p.Send(ctx, "app-1", 3))
+	require.NoError(t, p.Flush(ctx))
+
+	calls := rec.sent()
+	require.Len(t, calls, 2)
+	byApp := map[string][]interface{}{}
+	for _, c := range calls {
+		byApp[c.appID] = c.payloads
+		assert.NotEmpty(t, c.key)
+	}
+	assert.Equal(t, []interface{}{1, 3}, byApp["app-1"])
+	assert.Equal(t, []interface{}{2}, byApp["app-2"])
+
+	require.NoError(t, p.Close(ctx))
+	assert.ErrorIs(t, p.Send(ctx, "app-1", 4), ErrProducerClosed)
+	assert.ErrorIs(t, p.TrySend("app-1", 4), ErrProducerClosed)
+}
+
+func TestProducer_FlushInterval(t *testing.T) {
+	rec := &batchRecorder{}
+	p := NewProducer(rec, WithFlushInterval(5*time.Millisecond))
+	defer p.Close(context.Background())
+
+	require.NoError(t, p.Send(context.Background(), "app-1", "x"))
+	assert.Eventually(t, func() bool { return len(rec.sent()) == 1 }, time.Second, time.Millisecond)
+}
+
+func TestProducer_RetriesFailedRequest(t *testing.T) {
+	rec := &batchRecorder{requestErr: func(call int) error {
+		if call == 1 {
+			return &APIError{Code: 503, Message: "unavailable"}
+		}
+		return nil
+	}}
+	p := NewProducer(rec, WithRetryBackoff(time.Millisecond), WithFlushInterval(time.Hour))
+	ctx := context.Background()
+	for _, payload := range []string{"a", "b"} {
+		require.NoError(t, p.Send(ctx, "app-1", payload))
+	}
+	require.NoError(t, p.Close(ctx))
+
+	calls := rec.sent()
+	require.Len(t, calls, 2)
+	assert.Equal(t, calls[0].key, calls[1].key, "resending a failed request keeps its key")
+	assert.Equal(t, []interface{}{"a", "b"}, calls[1].payloads)
+}
+
+func TestProducer_RetriesItemErrors(t *testing.T) {
+	serverErr := &APIError{Code: 503, Message: "unavailable"}
+	badPayload := &APIError{Code: 400, Message: "invalid payload"}
+	rec := &batchRecorder{fn: func(call int, payloads []interface{}) []error {
+		switch call {
+		case 1: // every message failed in a successful response
+			return []error{serverErr, serverErr, serverErr}
+		case 2: // one message rejected, one still failing
+			return []error{nil, badPayload, serverErr}
+		default:
+			return nil
+		}
+	}}
+
+	var (
+		mu       sync.Mutex
+		failures []ProducerFailure
+	)
+	p := NewProducer(rec,
+		WithRetryBackoff(time.Millisecond),
+		WithFlushInterval(time.Hour),
+		WithFailureHandler(func(f ProducerFailure) {
+			mu.Lock()
+			defer mu.Unlock()
+			failures = append(failures, f)
+		}))
+	ctx := context.Background()
+	for _, payload := range []string{"a", "b", "c"} {
+		require.NoError(t, p.Send(ctx, "app-1", payload))
+	}
+	require.NoError(t, p.Close(ctx))
+
+	calls := rec.sent()
+	require.Len(t, calls, 3)
+	assert.NotEqual(t, calls[0].key, calls[1].key, "the API stored its answer under the first key")
+	assert.Equal(t, []interface{}{"a", "b", "c"}, calls[1].payloads)
+	assert.NotEqual(t, calls[1].key, calls[2].key, "resending part of a batch needs a new key")
+	assert.Equal(t, []interface{}{"c"}, calls[2].payloads)
+
+	require.Len(t, failures, 1)
+	assert.Equal(t, ProducerFailure{AppID: "app-1", Payload: "b", Attempts: 2, Err: badPayload}, failures[0])
+}
+
+func TestProducer_GivesUpAfterMaxAttempts(t *testing.T) {
+	rec := &batchRecorder{fn: func(int, []interface{}) []error {
+		return []error{&APIError{Code: 429, Message: "slow down"}}
+	}}
+	failed := make(chan ProducerFailure, 1)
+	p := NewProducer(rec, WithMaxAttempts(3), WithRetryBackoff(time.Millisecond),
+		WithFailureHandler(func(f ProducerFailure) { failed <- f }))
+
+	require.NoError(t, p.Send(context.Background(), "app-1", "x"))
+	require.NoError(t, p.Close(context.Background()))
+
+	f := <-failed
+	assert.Equal(t, 3, f.Attempts)
+	assert.ErrorIs(t, f.Err, ErrRateLimited)
+	assert.Len(t, rec.sent(), 3)
+}
+
+func TestProducer_CloseTimeout(t *testing.T) {
+	rec := &batchRecorder{fn: func(int, []interface{}) []error {
+		return []error{&APIError{Code: 500, Message: "boom"}}
+	}}
+	failed := make(chan ProducerFailure, 1)
+	p := NewProducer(rec, WithRetryBackoff(time.Hour),
+		WithFailureHandler(func(f ProducerFailure) { failed <- f }))
+	require.NoError(t, p.Send(context.Background(), "app-1", "x"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, p.Close(ctx), context.DeadlineExceeded)
+
+	f := <-failed
+	assert.True(t, errors.Is(f.Err, context.Canceled))
+}
+
+func TestProducer_CloseWithBlockedSend(t *testing.T) {
+	attempted := make(chan struct{}, 1)
+	rec := &batchRecorder{requestErr: func(int) error {
+		select {
+		case attempted <- struct{}{}:
+		default:
+		}
+		return &APIError{Code: 503, Message: "unavailable"}
+	}}
+	p := NewProducer(rec, WithQueueSize(1), WithBatchSize(1), WithWorkers(1), WithRetryBackoff(time.Hour))
+
+	// One message is backing off in the worker, one waits in the
+	// dispatcher, one in the queue, so the next Send blocks.
+	require.NoError(t, p.Send(context.Background(), "app-1", 0))
+	<-attempted
+	require.NoError(t, p.Send(context.Background(), "app-1", 1))
+	assert.Eventually(t, func() bool { return len(p.queue) == 0 }, time.Second, time.Millisecond)
+	require.NoError(t, p.Send(context.Background(), "app-1", 2))
+
+	sent := make(chan error, 1)
+	go func() { sent <- p.Send(context.Background(), "app-1", 3) }()
+	// Send holds closeMu for reading while it waits for room in the queue.
+	assert.Eventually(t, func() bool {
+		if p.closeMu.TryLock() {
+			p.closeMu.Unlock()
+			return false
+		}
+		return true
+	}, time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, p.Close(ctx), context.DeadlineExceeded)
+	assert.ErrorIs(t, <-sent, ErrProducerClosed)
+}
+
+func TestProducer_QueueFull(t *testing.T) {
+	block := make(chan struct{})
+	rec := &batchRecorder{fn: func(int, []interface{}) []error {
+		<-block
+		return nil
+	}}
+	p := NewProducer(rec, WithQueueSize(1), WithBatchSize(1), WithWorkers(1))
+	defer p.Close(context.Background())
+	defer close(block)
+
+	// One message is being sent, one waits in the dispatcher, one in the queue.
+	require.NoError(t, p.TrySend("app-1", 1))
+	assert.Eventually(t, func() bool { return len(rec.sent()) == 1 }, time.Second, time.Millisecond)
+	require.NoError(t, p.TrySend("app-1", 2))
+	assert.Eventually(t, func() bool { return len(p.queue) == 0 }, time.Second, time.Millisecond)
+	require.NoError(t, p.TrySend("app-1", 3))
+
+	assert.ErrorIs(t, p.TrySend("app-1", 4), ErrQueueFull)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, p.Send(ctx, "app-1", 4), context.DeadlineExceeded)
+}
+
+func TestIsRetryableSendError(t *testing.T) {
+	assert.True(t, isRetryableSendError(&APIError{Code: 502}))
+	assert.True(t, isRetryableSendError(&APIError{Code: 429}))
+	assert.True(t, isRetryableSendError(&APIError{Code: 408}))
+	assert.False(t, isRetryableSendError(&APIError{Code: 400}))
+	assert.False(t, isRetryableSendError(&APIError{Code: 200}))
+	assert.False(t, isRetryableSendError(context.Canceled))
+}
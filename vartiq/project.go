@@ -22,64 +22,54 @@ type CreateProjectRequest struct {
 	Description string `json:"description"`
 }
 
-type CreateProjectResponse struct {
-	Data    Project `json:"data"`
-	Message string  `json:"message"`
-	Success bool    `json:"success"`
-}
+// CreateProjectResponse is kept for compatibility; it is the same type as Response[Project].
+type CreateProjectResponse = Response[Project]
 
-func (s *ProjectService) Create(ctx context.Context, req *CreateProjectRequest) (*CreateProjectResponse, error) {
-	resp := &CreateProjectResponse{}
-	_, err := s.client.resty.R().
-		SetContext(ctx).
+func (s *ProjectService) Create(ctx context.Context, req *CreateProjectRequest, opts ...RequestOption) (*Response[Project], error) {
+	resp := &Response[Project]{}
+	httpResp, err := s.client.newRequest(ctx, opts...).
 		SetBody(req).
 		SetResult(resp).
 		Post("/projects")
 	if err != nil {
 		return nil, err
 	}
+	if err := checkResponse(httpResp); err != nil {
+		return nil, err
+	}
+	resp.setMeta(httpResp)
 	return resp, nil
 }
 
 // List all projects
-func (s *ProjectService) List(ctx context.Context) (*struct {
-	Data    []Project `json:"data"`
-	Message string    `json:"message"`
-	Success bool      `json:"success"`
-}, error) {
-	resp := &struct {
-		Data    []Project `json:"data"`
-		Message string    `json:"message"`
-		Success bool      `json:"success"`
-	}{}
-	_, err := s.client.resty.R().
-		SetContext(ctx).
+func (s *ProjectService) List(ctx context.Context) (*Response[[]Project], error) {
+	resp := &Response[[]Project]{}
+	httpResp, err := s.client.newRequest(ctx).
 		SetResult(resp).
 		Get("/projects")
 	if err != nil {
 		return nil, err
 	}
+	if err := checkResponse(httpResp); err != nil {
+		return nil, err
+	}
+	resp.setMeta(httpResp)
 	return resp, nil
 }
 
 // Get a single project by ID
-func (s *ProjectService) Get(ctx context.Context, projectID string) (*struct {
-	Data    Project `json:"data"`
-	Message string  `json:"message"`
-	Success bool    `json:"success"`
-}, error) {
-	resp := &struct {
-		Data    Project `json:"data"`
-		Message string  `json:"message"`
-		Success bool    `json:"success"`
-	}{}
-	_, err := s.client.resty.R().
-		SetContext(ctx).
+func (s *ProjectService) Get(ctx context.Context, projectID string) (*Response[Project], error) {
+	resp := &Response[Project]{}
+	httpResp, err := s.client.newRequest(ctx).
 		SetResult(resp).
 		Get("/projects/" + projectID)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkResponse(httpResp); err != nil {
+		return nil, err
+	}
+	resp.setMeta(httpResp)
 	return resp, nil
 }
 
@@ -90,31 +80,28 @@ type UpdateProjectRequest struct {
 }
 
 // Update a project by ID
-func (s *ProjectService) Update(ctx context.Context, projectID string, req *UpdateProjectRequest) (*struct {
-	Data    Project `json:"data"`
-	Message string  `json:"message"`
-	Success bool    `json:"success"`
-}, error) {
-	resp := &struct {
-		Data    Project `json:"data"`
-		Message string  `json:"message"`
-		Success bool    `json:"success"`
-	}{}
-	_, err := s.client.resty.R().
-		SetContext(ctx).
+func (s *ProjectService) Update(ctx context.Context, projectID string, req *UpdateProjectRequest) (*Response[Project], error) {
+	resp := &Response[Project]{}
+	httpResp, err := s.client.newRequest(ctx).
 		SetBody(req).
 		SetResult(resp).
 		Put("/projects/" + projectID)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkResponse(httpResp); err != nil {
+		return nil, err
+	}
+	resp.setMeta(httpResp)
 	return resp, nil
 }
 
 // Delete a project by ID
 func (s *ProjectService) Delete(ctx context.Context, projectID string) error {
-	_, err := s.client.resty.R().
-		SetContext(ctx).
+	httpResp, err := s.client.newRequest(ctx).
 		Delete("/projects/" + projectID)
-	return err
+	if err != nil {
+		return err
+	}
+	return checkResponse(httpResp)
 }
@@ -0,0 +1,35 @@
+package vartiq
+
+import (
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Response is the envelope returned by every Vartiq API call. Data holds the
+// decoded resource; the remaining fields describe the HTTP exchange.
+type Response[T any] struct {
+	Data    T      `json:"data"`
+	Message string `json:"message"`
+	Success bool   `json:"success"`
+
+	// StatusCode and Header are taken from the HTTP response.
+	StatusCode int         `json:"-"`
+	Header     http.Header `json:"-"`
+	// RequestID identifies the request in Vartiq's logs, when provided.
+	RequestID string `json:"-"`
+
+	// IdempotencyKey is the key sent with the request, if any.
+	IdempotencyKey string `json:"-"`
+	// Replayed reports whether the API returned the stored result of an
+	// earlier request with the same idempotency key.
+	Replayed bool `json:"-"`
+}
+
+// setMeta records the HTTP details of resp on r.
+func (r *Response[T]) setMeta(resp *resty.Response) {
+	r.StatusCode = resp.StatusCode()
+	r.Header = resp.Header()
+	r.RequestID = resp.Header().Get(requestIDHeader)
+	r.IdempotencyKey, r.Replayed = idempotencyResult(resp)
+}
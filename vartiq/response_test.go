@@ -0,0 +1,47 @@
+package vartiq
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dataOf is written once for every service, which is the point of Response[T].
+func dataOf[T any](resp *Response[T], err error) (T, error) {
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return resp.Data, nil
+}
+
+func TestResponse_Metadata(t *testing.T) {
+	client := newTestServer(t, http.StatusOK,
+		`{"data":[{"id":"a1","name":"App"}],"message":"Apps retrieved","success":true}`,
+		http.Header{"X-Request-Id": {"req-789"}})
+
+	resp, err := client.App.List(context.Background(), "project-1")
+	require.NoError(t, err)
+	assert.Equal(t, []App{{ID: "a1", Name: "App"}}, resp.Data)
+	assert.Equal(t, "Apps retrieved", resp.Message)
+	assert.True(t, resp.Success)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "req-789", resp.RequestID)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+}
+
+func TestResponse_Generic(t *testing.T) {
+	client := newTestServer(t, http.StatusOK, `{"data":{"id":"p1","name":"Project"},"success":true}`, nil)
+
+	project, err := dataOf(client.Project.Get(context.Background(), "p1"))
+	require.NoError(t, err)
+	assert.Equal(t, "p1", project.ID)
+
+	var created *CreateProjectResponse
+	created, err = client.Project.Create(context.Background(), &CreateProjectRequest{Name: "Project"})
+	require.NoError(t, err)
+	assert.Equal(t, "Project", created.Data.Name)
+}
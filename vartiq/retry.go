@@ -0,0 +1,149 @@
+package vartiq
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// IdempotencyKeyHeader is the request header carrying an idempotency key.
+// POST requests are only retried when it is set.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// RetryPolicy controls automatic retries of failed requests. Only requests
+// that are safe to repeat are retried: GET, PUT and DELETE always, POST only
+// when it carries an idempotency key.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Values below 2 disable retries.
+	MaxAttempts int
+
+	// MinBackoff and MaxBackoff bound the exponential backoff with jitter
+	// applied between attempts. A Retry-After response header replaces the
+	// computed delay, capped at MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// RetryableStatusCodes lists the HTTP statuses that trigger a retry.
+	RetryableStatusCodes []int
+
+	// RetryableError reports whether a transport error should be retried.
+	// Defaults to IsRetryableError when nil.
+	RetryableError func(error) bool
+}
+
+// DefaultRetryPolicy returns the policy used by clients created without
+// WithRetryPolicy: three attempts, backing off from 500ms up to 30s, on
+// timeouts, rate limiting, server errors and transient network failures.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  500 * time.Millisecond,
+		MaxBackoff:  30 * time.Second,
+		RetryableStatusCodes: []int{
+			http.StatusRequestTimeout,
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// WithRetryPolicy replaces the default retry policy. Pass a zero RetryPolicy
+// to disable retries.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *clientOptions) {
+		o.retry = policy
+	}
+}
+
+// IsRetryableError reports whether err is a transient network failure such
+// as a timeout, connection reset or refused connection. Cancellation and
+// certificate errors are never retried; an expired request context stops
+// retries regardless of this check.
+func IsRetryableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var certErr *x509.UnknownAuthorityError
+	var hostErr x509.HostnameError
+	if errors.As(err, &certErr) || errors.As(err, &hostErr) {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// apply installs the policy on the underlying resty client.
+func (p RetryPolicy) apply(r *resty.Client) {
+	if p.MaxAttempts < 2 {
+		return
+	}
+	r.SetRetryCount(p.MaxAttempts - 1).
+		SetRetryWaitTime(p.MinBackoff).
+		SetRetryMaxWaitTime(p.MaxBackoff).
+		SetRetryAfter(retryAfter).
+		AddRetryCondition(p.shouldRetry)
+}
+
+// shouldRetry is the resty retry condition implementing the policy.
+func (p RetryPolicy) shouldRetry(resp *resty.Response, err error) bool {
+	if resp == nil || resp.Request == nil || !isIdempotent(resp.Request) {
+		return false
+	}
+	if err != nil {
+		retryable := p.RetryableError
+		if retryable == nil {
+			retryable = IsRetryableError
+		}
+		return retryable(err)
+	}
+	for _, code := range p.RetryableStatusCodes {
+		if resp.StatusCode() == code {
+			return true
+		}
+	}
+	return false
+}
+
+// isIdempotent reports whether req may safely be sent more than once.
+func isIdempotent(req *resty.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get(IdempotencyKeyHeader) != ""
+}
+
+// retryAfter honors the Retry-After response header, given either in seconds
+// or as an HTTP date. Returning zero falls back to exponential backoff.
+func retryAfter(_ *resty.Client, resp *resty.Response) (time.Duration, error) {
+	value := resp.Header().Get("Retry-After")
+	if value == "" {
+		return 0, nil
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second, nil
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d, nil
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,119 @@
+package vartiq
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFlakyServer fails the first failures requests with status, then succeeds.
+func newFlakyServer(t *testing.T, failures int32, status int, header http.Header) (*httptest.Server, *int32) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if atomic.AddInt32(&calls, 1) <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"message":"try again"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"id":"p1"},"message":"ok","success":true}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func fastRetryPolicy() RetryPolicy {
+	p := DefaultRetryPolicy()
+	p.MinBackoff = time.Millisecond
+	p.MaxBackoff = 5 * time.Millisecond
+	return p
+}
+
+func TestRetry_IdempotentRequest(t *testing.T) {
+	srv, calls := newFlakyServer(t, 2, http.StatusBadGateway, nil)
+	client := NewClient("test-key", WithBaseURL(srv.URL), WithRetryPolicy(fastRetryPolicy()))
+
+	resp, err := client.Project.Get(context.Background(), "p1")
+	require.NoError(t, err)
+	assert.Equal(t, "p1", resp.Data.ID)
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+}
+
+func TestRetry_GivesUpAfterMaxAttempts(t *testing.T) {
+	srv, calls := newFlakyServer(t, 10, http.StatusServiceUnavailable, nil)
+	client := NewClient("test-key", WithBaseURL(srv.URL), WithRetryPolicy(fastRetryPolicy()))
+
+	_, err := client.Project.Get(context.Background(), "p1")
+	assert.ErrorIs(t, err, ErrServer)
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+}
+
+func TestRetry_NonRetryableStatus(t *testing.T) {
+	srv, calls := newFlakyServer(t, 10, http.StatusNotFound, nil)
+	client := NewClient("test-key", WithBaseURL(srv.URL), WithRetryPolicy(fastRetryPolicy()))
+
+	_, err := client.Project.Get(context.Background(), "p1")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+func TestRetry_PostWithoutIdempotencyKey(t *testing.T) {
+	srv, calls := newFlakyServer(t, 1, http.StatusBadGateway, nil)
+	client := NewClient("test-key", WithBaseURL(srv.URL), WithRetryPolicy(fastRetryPolicy()))
+
+	_, err := client.Project.Create(context.Background(), &CreateProjectRequest{Name: "Test"})
+	assert.ErrorIs(t, err, ErrServer)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+func TestRetry_PostWithIdempotencyKey(t *testing.T) {
+	srv, calls := newFlakyServer(t, 1, http.StatusBadGateway, nil)
+	client := NewClient("test-key", WithBaseURL(srv.URL), WithRetryPolicy(fastRetryPolicy()))
+
+	resp, err := client.Project.Create(context.Background(), &CreateProjectRequest{Name: "Test"}, WithIdempotencyKey("key-1"))
+	require.NoError(t, err)
+	assert.Equal(t, "p1", resp.Data.ID)
+	assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+}
+
+func TestRetry_HonorsRetryAfter(t *testing.T) {
+	srv, calls := newFlakyServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"1"}})
+	policy := fastRetryPolicy()
+	policy.MaxBackoff = 2 * time.Second
+	client := NewClient("test-key", WithBaseURL(srv.URL), WithRetryPolicy(policy))
+
+	start := time.Now()
+	_, err := client.Project.Get(context.Background(), "p1")
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+}
+
+func TestRetry_Disabled(t *testing.T) {
+	srv, calls := newFlakyServer(t, 1, http.StatusBadGateway, nil)
+	client := NewClient("test-key", WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{}))
+
+	_, err := client.Project.Get(context.Background(), "p1")
+	assert.ErrorIs(t, err, ErrServer)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+func TestIsRetryableError(t *testing.T) {
+	assert.True(t, IsRetryableError(io.ErrUnexpectedEOF))
+	assert.True(t, IsRetryableError(syscall.ECONNRESET))
+	assert.False(t, IsRetryableError(nil))
+	assert.False(t, IsRetryableError(context.Canceled))
+	assert.False(t, IsRetryableError(errors.New("boom")))
+}
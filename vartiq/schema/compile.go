@@ -0,0 +1,493 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// defaultBase is the base URI of documents without a root $id.
+const defaultBase = "vartiq-schema:///root.json"
+
+// node is a compiled schema or subschema.
+type node struct {
+	// location is the node's JSON pointer in the schema document.
+	location string
+	// always is set for the boolean schemas true and false.
+	always *bool
+
+	ref     string
+	refNode *node
+
+	types    []string
+	enum     []interface{}
+	constVal interface{}
+	hasConst bool
+
+	multipleOf       *number
+	maximum          *number
+	exclusiveMaximum *number
+	minimum          *number
+	exclusiveMinimum *number
+
+	maxLength int
+	minLength int
+	pattern   *regexp.Regexp
+
+	maxItems    int
+	minItems    int
+	uniqueItems bool
+	maxContains int
+	minContains int
+
+	maxProperties     int
+	minProperties     int
+	required          []string
+	dependentRequired map[string][]string
+
+	allOf []*node
+	anyOf []*node
+	oneOf []*node
+	not   *node
+
+	ifNode   *node
+	thenNode *node
+	elseNode *node
+
+	properties           map[string]*node
+	patternProperties    []patternNode
+	additionalProperties *node
+	propertyNames        *node
+	dependentSchemas     map[string]*node
+
+	prefixItems []*node
+	items       *node
+	contains    *node
+}
+
+type patternNode struct {
+	re   *regexp.Regexp
+	node *node
+}
+
+// number is a numeric keyword value, kept exact for comparisons.
+type number struct {
+	rat  *big.Rat
+	text string
+}
+
+// unsupported lists keywords whose absence would silently accept invalid
+// values, so Compile rejects schemas using them.
+var unsupported = []string{"$dynamicRef", "$recursiveRef", "unevaluatedProperties", "unevaluatedItems"}
+
+// compiler holds the state of one Compile call.
+type compiler struct {
+	// nodes indexes every subschema by its absolute URI: the URI of each
+	// enclosing resource with the JSON pointer from it as fragment.
+	nodes map[string]*node
+	refs  []*node
+}
+
+// scope is a schema resource enclosing the node being compiled.
+type scope struct {
+	base     *url.URL
+	location string
+}
+
+func compile(doc interface{}) (*node, error) {
+	base, _ := url.Parse(defaultBase)
+	c := &compiler{nodes: make(map[string]*node)}
+	if obj, ok := doc.(map[string]interface{}); ok {
+		if s, ok := obj["$schema"]; ok {
+			uri, _ := s.(string)
+			if strings.TrimSuffix(uri, "#") != Draft {
+				return nil, fmt.Errorf("schema: unsupported $schema %v, only %s is supported", s, Draft)
+			}
+		}
+	}
+	root, err := c.compile(doc, "", []scope{{base: base}})
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range c.refs {
+		if n.refNode = c.nodes[n.ref]; n.refNode == nil {
+			return nil, fmt.Errorf("schema: %s/$ref: cannot resolve %s", n.location, n.ref)
+		}
+	}
+	return root, nil
+}
+
+func (c *compiler) compile(v interface{}, location string, scopes []scope) (*node, error) {
+	n := &node{location: location, maxLength: -1, maxItems: -1, maxProperties: -1, maxContains: -1, minContains: 1}
+	if b, ok := v.(bool); ok {
+		n.always = &b
+		c.register(n, scopes)
+		return n, nil
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("schema: %s: must be an object or boolean", pointerOrRoot(location))
+	}
+	for _, kw := range unsupported {
+		if _, ok := obj[kw]; ok {
+			return nil, fmt.Errorf("schema: %s/%s: keyword is not supported", location, kw)
+		}
+	}
+
+	if id, ok := obj["$id"]; ok {
+		s, ok := id.(string)
+		if !ok {
+			return nil, c.errorf(location, "$id", "must be a string")
+		}
+		ref, err := url.Parse(s)
+		if err != nil || ref.Fragment != "" {
+			return nil, c.errorf(location, "$id", "must be a URI without fragment")
+		}
+		scopes = append(scopes[:len(scopes):len(scopes)], scope{base: scopes[len(scopes)-1].base.ResolveReference(ref), location: location})
+	}
+	c.register(n, scopes)
+	if anchor, ok := obj["$anchor"]; ok {
+		s, ok := anchor.(string)
+		if !ok || s == "" {
+			return nil, c.errorf(location, "$anchor", "must be a non-empty string")
+		}
+		c.nodes[withFragment(scopes[len(scopes)-1].base, s)] = n
+	}
+	if ref, ok := obj["$ref"]; ok {
+		s, ok := ref.(string)
+		if !ok {
+			return nil, c.errorf(location, "$ref", "must be a string")
+		}
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, c.errorf(location, "$ref", "must be a URI reference")
+		}
+		abs := scopes[len(scopes)-1].base.ResolveReference(u)
+		n.ref = withFragment(abs, abs.Fragment)
+		c.refs = append(c.refs, n)
+	}
+
+	sub := func(kw string) (*node, error) {
+		v, ok := obj[kw]
+		if !ok {
+			return nil, nil
+		}
+		return c.compile(v, location+"/"+kw, scopes)
+	}
+	subList := func(kw string) ([]*node, error) {
+		v, ok := obj[kw]
+		if !ok {
+			return nil, nil
+		}
+		list, ok := v.([]interface{})
+		if !ok || len(list) == 0 {
+			return nil, c.errorf(location, kw, "must be a non-empty array")
+		}
+		nodes := make([]*node, len(list))
+		for i, item := range list {
+			var err error
+			if nodes[i], err = c.compile(item, location+"/"+kw+"/"+strconv.Itoa(i), scopes); err != nil {
+				return nil, err
+			}
+		}
+		return nodes, nil
+	}
+	subMap := func(kw string) (map[string]*node, error) {
+		v, ok := obj[kw]
+		if !ok {
+			return nil, nil
+		}
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, c.errorf(location, kw, "must be an object")
+		}
+		nodes := make(map[string]*node, len(m))
+		for name, item := range m {
+			var err error
+			if nodes[name], err = c.compile(item, location+"/"+kw+"/"+escapePointer(name), scopes); err != nil {
+				return nil, err
+			}
+		}
+		return nodes, nil
+	}
+
+	var err error
+	if _, err = subMap("$defs"); err != nil {
+		return nil, err
+	}
+	if n.types, err = c.types(obj, location); err != nil {
+		return nil, err
+	}
+	if e, ok := obj["enum"]; ok {
+		if n.enum, ok = e.([]interface{}); !ok {
+			return nil, c.errorf(location, "enum", "must be an array")
+		}
+	}
+	n.constVal, n.hasConst = obj["const"]
+
+	for _, kw := range []struct {
+		name     string
+		dst      **number
+		positive bool
+	}{
+		{"multipleOf", &n.multipleOf, true},
+		{"maximum", &n.maximum, false},
+		{"exclusiveMaximum", &n.exclusiveMaximum, false},
+		{"minimum", &n.minimum, false},
+		{"exclusiveMinimum", &n.exclusiveMinimum, false},
+	} {
+		if *kw.dst, err = c.number(obj, location, kw.name, kw.positive); err != nil {
+			return nil, err
+		}
+	}
+	for _, kw := range []struct {
+		name string
+		dst  *int
+	}{
+		{"maxLength", &n.maxLength},
+		{"minLength", &n.minLength},
+		{"maxItems", &n.maxItems},
+		{"minItems", &n.minItems},
+		{"maxContains", &n.maxContains},
+		{"minContains", &n.minContains},
+		{"maxProperties", &n.maxProperties},
+		{"minProperties", &n.minProperties},
+	} {
+		if err = c.count(obj, location, kw.name, kw.dst); err != nil {
+			return nil, err
+		}
+	}
+	if p, ok := obj["pattern"]; ok {
+		if n.pattern, err = c.regexp(p, location, "pattern"); err != nil {
+			return nil, err
+		}
+	}
+	if u, ok := obj["uniqueItems"]; ok {
+		if n.uniqueItems, ok = u.(bool); !ok {
+			return nil, c.errorf(location, "uniqueItems", "must be a boolean")
+		}
+	}
+	if n.required, err = c.strings(obj["required"], location, "required"); err != nil {
+		return nil, err
+	}
+	if dr, ok := obj["dependentRequired"]; ok {
+		m, ok := dr.(map[string]interface{})
+		if !ok {
+			return nil, c.errorf(location, "dependentRequired", "must be an object")
+		}
+		n.dependentRequired = make(map[string][]string, len(m))
+		for name, list := range m {
+			if n.dependentRequired[name], err = c.strings(list, location, "dependentRequired/"+escapePointer(name)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if n.allOf, err = subList("allOf"); err != nil {
+		return nil, err
+	}
+	if n.anyOf, err = subList("anyOf"); err != nil {
+		return nil, err
+	}
+	if n.oneOf, err = subList("oneOf"); err != nil {
+		return nil, err
+	}
+	if n.not, err = sub("not"); err != nil {
+		return nil, err
+	}
+	if n.ifNode, err = sub("if"); err != nil {
+		return nil, err
+	}
+	if n.thenNode, err = sub("then"); err != nil {
+		return nil, err
+	}
+	if n.elseNode, err = sub("else"); err != nil {
+		return nil, err
+	}
+
+	if n.properties, err = subMap("properties"); err != nil {
+		return nil, err
+	}
+	patterns, err := subMap("patternProperties")
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range sortedKeys(patterns) {
+		re, err := c.regexp(p, location, "patternProperties/"+escapePointer(p))
+		if err != nil {
+			return nil, err
+		}
+		n.patternProperties = append(n.patternProperties, patternNode{re: re, node: patterns[p]})
+	}
+	if n.additionalProperties, err = sub("additionalProperties"); err != nil {
+		return nil, err
+	}
+	if n.propertyNames, err = sub("propertyNames"); err != nil {
+		return nil, err
+	}
+	if n.dependentSchemas, err = subMap("dependentSchemas"); err != nil {
+		return nil, err
+	}
+
+	if _, ok := obj["prefixItems"]; ok {
+		if n.prefixItems, err = subList("prefixItems"); err != nil {
+			return nil, err
+		}
+	}
+	if items, ok := obj["items"]; ok {
+		if _, isArray := items.([]interface{}); isArray {
+			return nil, c.errorf(location, "items", "must be a schema; use prefixItems for tuples")
+		}
+		if n.items, err = sub("items"); err != nil {
+			return nil, err
+		}
+	}
+	if n.contains, err = sub("contains"); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// register indexes n under every enclosing resource.
+func (c *compiler) register(n *node, scopes []scope) {
+	for _, s := range scopes {
+		c.nodes[withFragment(s.base, strings.TrimPrefix(n.location, s.location))] = n
+	}
+}
+
+func (c *compiler) errorf(location, keyword, format string, args ...interface{}) error {
+	return fmt.Errorf("schema: %s/%s: %s", location, keyword, fmt.Sprintf(format, args...))
+}
+
+var typeNames = map[string]bool{
+	"null": true, "boolean": true, "object": true, "array": true,
+	"number": true, "integer": true, "string": true,
+}
+
+func (c *compiler) types(obj map[string]interface{}, location string) ([]string, error) {
+	v, ok := obj["type"]
+	if !ok {
+		return nil, nil
+	}
+	var list []interface{}
+	switch t := v.(type) {
+	case string:
+		list = []interface{}{t}
+	case []interface{}:
+		list = t
+	default:
+		return nil, c.errorf(location, "type", "must be a string or an array of strings")
+	}
+	types := make([]string, 0, len(list))
+	for _, item := range list {
+		s, _ := item.(string)
+		if !typeNames[s] {
+			return nil, c.errorf(location, "type", "unknown type %v", item)
+		}
+		types = append(types, s)
+	}
+	return types, nil
+}
+
+func (c *compiler) number(obj map[string]interface{}, location, keyword string, positive bool) (*number, error) {
+	v, ok := obj[keyword]
+	if !ok {
+		return nil, nil
+	}
+	num, ok := toNumber(v)
+	if !ok || (positive && num.rat.Sign() <= 0) {
+		if positive {
+			return nil, c.errorf(location, keyword, "must be a number greater than 0")
+		}
+		return nil, c.errorf(location, keyword, "must be a number")
+	}
+	return num, nil
+}
+
+func (c *compiler) count(obj map[string]interface{}, location, keyword string, dst *int) error {
+	v, ok := obj[keyword]
+	if !ok {
+		return nil
+	}
+	num, ok := toNumber(v)
+	if !ok || !num.rat.IsInt() || num.rat.Sign() < 0 || !num.rat.Num().IsInt64() {
+		return c.errorf(location, keyword, "must be a non-negative integer")
+	}
+	*dst = int(num.rat.Num().Int64())
+	return nil
+}
+
+func (c *compiler) regexp(v interface{}, location, keyword string) (*regexp.Regexp, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, c.errorf(location, keyword, "must be a string")
+	}
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return nil, c.errorf(location, keyword, "invalid pattern: %v", err)
+	}
+	return re, nil
+}
+
+func (c *compiler) strings(v interface{}, location, keyword string) ([]string, error) {
+	if v == nil {
+		return nil, nil
+	}
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, c.errorf(location, keyword, "must be an array of strings")
+	}
+	out := make([]string, len(list))
+	for i, item := range list {
+		if out[i], ok = item.(string); !ok {
+			return nil, c.errorf(location, keyword, "must be an array of strings")
+		}
+	}
+	return out, nil
+}
+
+// toNumber converts a decoded JSON number.
+func toNumber(v interface{}) (*number, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return nil, false
+	}
+	r, ok := new(big.Rat).SetString(string(n))
+	if !ok {
+		return nil, false
+	}
+	return &number{rat: r, text: string(n)}, true
+}
+
+// withFragment returns base with fragment, as used for the node index.
+func withFragment(base *url.URL, fragment string) string {
+	u := *base
+	u.Fragment, u.RawFragment = "", ""
+	return u.String() + "#" + fragment
+}
+
+// escapePointer escapes a JSON pointer reference token (RFC 6901).
+func escapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+func pointerOrRoot(location string) string {
+	if location == "" {
+		return "(root)"
+	}
+	return location
+}
+
+func sortedKeys(m map[string]*node) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,97 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		schema string
+		err    string
+	}{
+		{`{`, "invalid JSON"},
+		{`1`, "(root): must be an object or boolean"},
+		{`{"$schema": "http://json-schema.org/draft-07/schema#"}`, "unsupported $schema"},
+		{`{"type": "float"}`, "/type: unknown type float"},
+		{`{"properties": {"a": {"minimum": "1"}}}`, "/properties/a/minimum: must be a number"},
+		{`{"multipleOf": 0}`, "/multipleOf: must be a number greater than 0"},
+		{`{"maxLength": -1}`, "/maxLength: must be a non-negative integer"},
+		{`{"minItems": 1.5}`, "/minItems: must be a non-negative integer"},
+		{`{"pattern": "("}`, "/pattern: invalid pattern"},
+		{`{"required": ["a", 1]}`, "/required: must be an array of strings"},
+		{`{"allOf": []}`, "/allOf: must be a non-empty array"},
+		{`{"items": [{}]}`, "/items: must be a schema; use prefixItems for tuples"},
+		{`{"properties": []}`, "/properties: must be an object"},
+		{`{"$ref": "#/$defs/missing"}`, "/$ref: cannot resolve"},
+		{`{"$ref": "https://example.com/other.json"}`, "/$ref: cannot resolve https://example.com/other.json#"},
+		{`{"properties": {"a": {"unevaluatedProperties": false}}}`, "/properties/a/unevaluatedProperties: keyword is not supported"},
+		{`{"$dynamicRef": "#meta"}`, "/$dynamicRef: keyword is not supported"},
+		{`{"$id": "https://example.com/a.json#frag"}`, "/$id: must be a URI without fragment"},
+	} {
+		_, err := Compile([]byte(tc.schema))
+		assert.ErrorContains(t, err, tc.err, tc.schema)
+	}
+}
+
+func TestCompile_References(t *testing.T) {
+	s := MustCompile(`{
+		"$id": "https://example.com/schemas/order.json",
+		"type": "object",
+		"properties": {
+			"id": {"$ref": "#/$defs/id"},
+			"item": {"$ref": "item.json"},
+			"price": {"$ref": "#price"},
+			"weird": {"$ref": "#/$defs/a~1b%25c"},
+			"parent": {"$ref": "#"}
+		},
+		"$defs": {
+			"id": {"type": "string"},
+			"a/b%c": {"const": 1},
+			"price": {"$anchor": "price", "type": "number", "minimum": 0},
+			"item": {
+				"$id": "item.json",
+				"type": "object",
+				"required": ["sku"],
+				"properties": {"price": {"$ref": "#/$defs/amount"}},
+				"$defs": {"amount": {"type": "integer"}}
+			}
+		}
+	}`)
+
+	assert.NoError(t, s.ValidateJSON([]byte(`{"id":"o1","item":{"sku":"a","price":3},"price":1.5,"weird":1,"parent":{"id":"o2"}}`)))
+
+	err := s.ValidateJSON([]byte(`{"id":1,"item":{"price":1.5},"price":-1,"weird":2,"parent":{"id":false}}`))
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	var got []string
+	for _, v := range verr.Violations {
+		got = append(got, v.InstanceLocation+" "+v.KeywordLocation)
+	}
+	assert.Equal(t, []string{
+		"/id /$defs/id/type",
+		"/item/sku /$defs/item/required",
+		"/item/price /$defs/item/$defs/amount/type",
+		"/parent/id /$defs/id/type",
+		"/price /$defs/price/minimum",
+		"/weird /$defs/a~1b%c/const",
+	}, got)
+}
+
+func TestCompile_RecursiveReference(t *testing.T) {
+	tree := MustCompile(`{
+		"type": "object",
+		"properties": {
+			"value": {"type": "integer"},
+			"children": {"type": "array", "items": {"$ref": "#"}}
+		}
+	}`)
+	assert.NoError(t, tree.ValidateJSON([]byte(`{"value":1,"children":[{"value":2,"children":[{"value":3}]}]}`)))
+	err := tree.ValidateJSON([]byte(`{"children":[{"children":[{"value":"x"}]}]}`))
+	assert.EqualError(t, err, "schema: /children/0/children/0/value: must be of type integer, got string")
+
+	loop := MustCompile(`{"$defs": {"a": {"$ref": "#/$defs/b"}, "b": {"$ref": "#/$defs/a"}}, "$ref": "#/$defs/a"}`)
+	assert.ErrorContains(t, loop.ValidateJSON([]byte(`{}`)), "exceeds the maximum schema depth")
+}
@@ -0,0 +1,147 @@
+// Package schema validates JSON values against JSON Schema documents.
+//
+// It implements the core and validation keywords of JSON Schema draft
+// 2020-12: type, enum, const, the numeric, string, array and object
+// constraints, the applicators (allOf, anyOf, oneOf, not, if/then/else,
+// properties, patternProperties, additionalProperties, prefixItems, items,
+// contains, propertyNames, dependentSchemas) and $ref to $defs, $anchor and
+// $id within the same document.
+//
+//	s, err := schema.Compile([]byte(`{
+//	    "type": "object",
+//	    "required": ["invoiceId", "amount"],
+//	    "properties": {
+//	        "invoiceId": {"type": "string"},
+//	        "amount": {"type": "integer", "minimum": 0}
+//	    }
+//	}`))
+//	...
+//	if err := s.ValidateJSON(body); err != nil {
+//	    var verr *schema.ValidationError
+//	    errors.As(err, &verr)
+//	    for _, v := range verr.Violations {
+//	        fmt.Println(v.InstanceLocation, v.Message) // e.g. "/amount must be >= 0"
+//	    }
+//	}
+//
+// Remote references, $dynamicRef, unevaluatedProperties and unevaluatedItems
+// are not supported; Compile rejects schemas using them rather than
+// ignoring them. The format keyword is treated as an annotation, as the
+// specification defaults to. Patterns use Go's RE2 syntax, which covers the
+// ECMA-262 features common in schemas but not lookaround or backreferences.
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Draft is the $schema URI of the supported JSON Schema version. Schemas
+// declaring any other $schema are rejected.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a compiled JSON Schema. It is safe for concurrent use.
+type Schema struct {
+	root *node
+}
+
+// Compile parses and compiles a JSON Schema document.
+func Compile(document []byte) (*Schema, error) {
+	var v interface{}
+	if err := decodeJSON(document, &v); err != nil {
+		return nil, fmt.Errorf("schema: invalid JSON: %w", err)
+	}
+	root, err := compile(v)
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{root: root}, nil
+}
+
+// MustCompile is like Compile but panics if the document is invalid. It
+// simplifies initializing package-level schemas.
+func MustCompile(document string) *Schema {
+	s, err := Compile([]byte(document))
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Validate checks v against the schema. v may be any value encoding/json
+// can marshal; it is validated as its JSON encoding. The error is a
+// *ValidationError when v does not conform.
+func (s *Schema) Validate(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("schema: encode value: %w", err)
+	}
+	return s.ValidateJSON(data)
+}
+
+// ValidateJSON checks a JSON document against the schema, e.g. a webhook
+// body as received. The error is a *ValidationError when the document does
+// not conform.
+func (s *Schema) ValidateJSON(data []byte) error {
+	var v interface{}
+	if err := decodeJSON(data, &v); err != nil {
+		return fmt.Errorf("schema: invalid JSON: %w", err)
+	}
+	vr := &validator{}
+	s.root.validate(v, "", vr)
+	if len(vr.violations) > 0 {
+		return &ValidationError{Violations: vr.violations}
+	}
+	return nil
+}
+
+// Violation is one way in which a value fails its schema.
+type Violation struct {
+	// InstanceLocation is the JSON pointer (RFC 6901) to the offending
+	// part of the value; "" is the value itself. For a missing required
+	// property it points to where the property should be.
+	InstanceLocation string
+	// KeywordLocation is the JSON pointer to the failing keyword in the
+	// schema document, e.g. "/properties/amount/minimum".
+	KeywordLocation string
+	Message         string
+}
+
+func (v Violation) String() string {
+	loc := v.InstanceLocation
+	if loc == "" {
+		loc = "(root)"
+	}
+	return loc + ": " + v.Message
+}
+
+// ValidationError reports every violation found in a value, in the order
+// the schema was evaluated.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.String()
+	}
+	return "schema: " + strings.Join(msgs, "; ")
+}
+
+// decodeJSON decodes a single JSON value, keeping numbers exact.
+func decodeJSON(data []byte, v *interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("unexpected data after JSON value")
+	}
+	return nil
+}
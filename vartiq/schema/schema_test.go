@@ -0,0 +1,82 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const invoiceSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"required": ["invoiceId", "amount", "customer"],
+	"properties": {
+		"invoiceId": {"type": "string", "pattern": "^inv_"},
+		"amount": {"type": "integer", "minimum": 0},
+		"customer": {
+			"type": "object",
+			"required": ["email"],
+			"properties": {"email": {"type": "string"}}
+		},
+		"lines": {"type": "array", "items": {"$ref": "#/$defs/line"}}
+	},
+	"additionalProperties": false,
+	"$defs": {
+		"line": {"type": "object", "required": ["sku"]}
+	}
+}`
+
+func TestSchema_ValidateJSON(t *testing.T) {
+	s := MustCompile(invoiceSchema)
+
+	require.NoError(t, s.ValidateJSON([]byte(`{"invoiceId":"inv_1","amount":4200,"customer":{"email":"a@b.c"}}`)))
+
+	err := s.ValidateJSON([]byte(`{"invoiceId":"x","amount":-1.5,"customer":{},"lines":[{"sku":"a"},{}],"extra":true}`))
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr))
+	assert.Equal(t, []Violation{
+		{InstanceLocation: "/amount", KeywordLocation: "/properties/amount/type", Message: "must be of type integer, got number"},
+		{InstanceLocation: "/amount", KeywordLocation: "/properties/amount/minimum", Message: "must be >= 0"},
+		{InstanceLocation: "/customer/email", KeywordLocation: "/properties/customer/required", Message: "is required"},
+		{InstanceLocation: "/extra", KeywordLocation: "/additionalProperties", Message: "is not allowed"},
+		{InstanceLocation: "/invoiceId", KeywordLocation: "/properties/invoiceId/pattern", Message: `must match pattern "^inv_"`},
+		{InstanceLocation: "/lines/1/sku", KeywordLocation: "/$defs/line/required", Message: "is required"},
+	}, verr.Violations)
+	assert.Equal(t, "schema: /amount: must be of type integer, got number; /amount: must be >= 0; "+
+		"/customer/email: is required; /extra: is not allowed; /invoiceId: must match pattern \"^inv_\"; "+
+		"/lines/1/sku: is required", err.Error())
+
+	err = s.ValidateJSON([]byte(`[]`))
+	assert.EqualError(t, err, "schema: (root): must be of type object, got array")
+
+	err = s.ValidateJSON([]byte(`{"a":1} {}`))
+	assert.ErrorContains(t, err, "invalid JSON")
+}
+
+func TestSchema_Validate(t *testing.T) {
+	type customer struct {
+		Email string `json:"email"`
+	}
+	type invoice struct {
+		InvoiceID string    `json:"invoiceId"`
+		Amount    int       `json:"amount"`
+		Customer  *customer `json:"customer,omitempty"`
+	}
+	s := MustCompile(invoiceSchema)
+
+	assert.NoError(t, s.Validate(invoice{InvoiceID: "inv_1", Amount: 1, Customer: &customer{Email: "x"}}))
+	assert.NoError(t, s.Validate(map[string]interface{}{"invoiceId": "inv_1", "amount": 1.0, "customer": map[string]string{"email": "x"}}))
+
+	err := s.Validate(invoice{InvoiceID: "inv_1"})
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr))
+	assert.Equal(t, "/customer", verr.Violations[0].InstanceLocation)
+
+	assert.ErrorContains(t, s.Validate(func() {}), "encode value")
+}
+
+func TestMustCompile_Panics(t *testing.T) {
+	assert.Panics(t, func() { MustCompile(`{"type": 1}`) })
+}
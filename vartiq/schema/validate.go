@@ -0,0 +1,378 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxDepth bounds how deeply $ref may recurse on the same value, so that
+// a schema referring to itself cannot loop forever.
+const maxDepth = 512
+
+// validator collects the violations found while validating a value.
+type validator struct {
+	violations []Violation
+	depth      int
+}
+
+func (vr *validator) fail(inst, keywordLocation, format string, args ...interface{}) {
+	vr.violations = append(vr.violations, Violation{
+		InstanceLocation: inst,
+		KeywordLocation:  keywordLocation,
+		Message:          fmt.Sprintf(format, args...),
+	})
+}
+
+// matches reports whether v is valid against n, without recording why not.
+func (n *node) matches(v interface{}, inst string, vr *validator) bool {
+	sub := &validator{depth: vr.depth}
+	n.validate(v, inst, sub)
+	return len(sub.violations) == 0
+}
+
+// validate records every violation of n by v, which is located at the JSON
+// pointer inst.
+func (n *node) validate(v interface{}, inst string, vr *validator) {
+	if n.always != nil {
+		if !*n.always {
+			vr.fail(inst, n.location, "is not allowed")
+		}
+		return
+	}
+	kw := func(name string) string { return n.location + "/" + name }
+
+	if n.refNode != nil {
+		if vr.depth >= maxDepth {
+			vr.fail(inst, kw("$ref"), "exceeds the maximum schema depth of %d", maxDepth)
+			return
+		}
+		vr.depth++
+		n.refNode.validate(v, inst, vr)
+		vr.depth--
+	}
+
+	if len(n.types) > 0 && !hasType(v, n.types) {
+		if len(n.types) == 1 {
+			vr.fail(inst, kw("type"), "must be of type %s, got %s", n.types[0], typeOf(v))
+		} else {
+			vr.fail(inst, kw("type"), "must be one of types %s, got %s", strings.Join(n.types, ", "), typeOf(v))
+		}
+	}
+	if n.enum != nil {
+		found := false
+		for _, e := range n.enum {
+			if equal(v, e) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			vr.fail(inst, kw("enum"), "must be one of %s", formatValues(n.enum))
+		}
+	}
+	if n.hasConst && !equal(v, n.constVal) {
+		vr.fail(inst, kw("const"), "must be %s", formatValue(n.constVal))
+	}
+
+	switch x := v.(type) {
+	case json.Number:
+		n.validateNumber(x, inst, vr)
+	case string:
+		n.validateString(x, inst, vr)
+	case []interface{}:
+		n.validateArray(x, inst, vr)
+	case map[string]interface{}:
+		n.validateObject(x, inst, vr)
+	}
+
+	for _, sub := range n.allOf {
+		sub.validate(v, inst, vr)
+	}
+	if n.anyOf != nil {
+		matched := false
+		for _, sub := range n.anyOf {
+			if sub.matches(v, inst, vr) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			vr.fail(inst, kw("anyOf"), "must match at least one schema in anyOf")
+		}
+	}
+	if n.oneOf != nil {
+		var matched []string
+		for i, sub := range n.oneOf {
+			if sub.matches(v, inst, vr) {
+				matched = append(matched, strconv.Itoa(i))
+			}
+		}
+		switch len(matched) {
+		case 1:
+		case 0:
+			vr.fail(inst, kw("oneOf"), "must match exactly one schema in oneOf, matched none")
+		default:
+			vr.fail(inst, kw("oneOf"), "must match exactly one schema in oneOf, matched %s", strings.Join(matched, ", "))
+		}
+	}
+	if n.not != nil && n.not.matches(v, inst, vr) {
+		vr.fail(inst, kw("not"), "must not match the schema in not")
+	}
+	if n.ifNode != nil {
+		if n.ifNode.matches(v, inst, vr) {
+			if n.thenNode != nil {
+				n.thenNode.validate(v, inst, vr)
+			}
+		} else if n.elseNode != nil {
+			n.elseNode.validate(v, inst, vr)
+		}
+	}
+}
+
+func (n *node) validateNumber(x json.Number, inst string, vr *validator) {
+	kw := func(name string) string { return n.location + "/" + name }
+	r, ok := new(big.Rat).SetString(string(x))
+	if !ok {
+		// The exponent is beyond what big.Rat accepts. Fail closed rather
+		// than skip the constraints the number cannot be compared with.
+		for _, c := range []struct {
+			name  string
+			limit *number
+		}{
+			{"multipleOf", n.multipleOf},
+			{"maximum", n.maximum},
+			{"exclusiveMaximum", n.exclusiveMaximum},
+			{"minimum", n.minimum},
+			{"exclusiveMinimum", n.exclusiveMinimum},
+		} {
+			if c.limit != nil {
+				vr.fail(inst, kw(c.name), "cannot be checked against %s %s: number out of range", c.name, c.limit.text)
+			}
+		}
+		return
+	}
+	if n.multipleOf != nil && !new(big.Rat).Quo(r, n.multipleOf.rat).IsInt() {
+		vr.fail(inst, kw("multipleOf"), "must be a multiple of %s", n.multipleOf.text)
+	}
+	if n.maximum != nil && r.Cmp(n.maximum.rat) > 0 {
+		vr.fail(inst, kw("maximum"), "must be <= %s", n.maximum.text)
+	}
+	if n.exclusiveMaximum != nil && r.Cmp(n.exclusiveMaximum.rat) >= 0 {
+		vr.fail(inst, kw("exclusiveMaximum"), "must be < %s", n.exclusiveMaximum.text)
+	}
+	if n.minimum != nil && r.Cmp(n.minimum.rat) < 0 {
+		vr.fail(inst, kw("minimum"), "must be >= %s", n.minimum.text)
+	}
+	if n.exclusiveMinimum != nil && r.Cmp(n.exclusiveMinimum.rat) <= 0 {
+		vr.fail(inst, kw("exclusiveMinimum"), "must be > %s", n.exclusiveMinimum.text)
+	}
+}
+
+func (n *node) validateString(x string, inst string, vr *validator) {
+	kw := func(name string) string { return n.location + "/" + name }
+	length := utf8.RuneCountInString(x)
+	if n.maxLength >= 0 && length > n.maxLength {
+		vr.fail(inst, kw("maxLength"), "must be at most %d characters long", n.maxLength)
+	}
+	if length < n.minLength {
+		vr.fail(inst, kw("minLength"), "must be at least %d characters long", n.minLength)
+	}
+	if n.pattern != nil && !n.pattern.MatchString(x) {
+		vr.fail(inst, kw("pattern"), "must match pattern %q", n.pattern.String())
+	}
+}
+
+func (n *node) validateArray(x []interface{}, inst string, vr *validator) {
+	kw := func(name string) string { return n.location + "/" + name }
+	if n.maxItems >= 0 && len(x) > n.maxItems {
+		vr.fail(inst, kw("maxItems"), "must have at most %d items", n.maxItems)
+	}
+	if len(x) < n.minItems {
+		vr.fail(inst, kw("minItems"), "must have at least %d items", n.minItems)
+	}
+	if n.uniqueItems {
+	unique:
+		for i := range x {
+			for j := i + 1; j < len(x); j++ {
+				if equal(x[i], x[j]) {
+					vr.fail(inst, kw("uniqueItems"), "must not contain duplicates, items %d and %d are equal", i, j)
+					break unique
+				}
+			}
+		}
+	}
+
+	for i, item := range x {
+		itemInst := inst + "/" + strconv.Itoa(i)
+		switch {
+		case i < len(n.prefixItems):
+			n.prefixItems[i].validate(item, itemInst, vr)
+		case n.items != nil:
+			n.items.validate(item, itemInst, vr)
+		}
+	}
+
+	if n.contains != nil {
+		count := 0
+		for i, item := range x {
+			if n.contains.matches(item, inst+"/"+strconv.Itoa(i), vr) {
+				count++
+			}
+		}
+		if count < n.minContains {
+			vr.fail(inst, kw("contains"), "must contain at least %d matching items, found %d", n.minContains, count)
+		}
+		if n.maxContains >= 0 && count > n.maxContains {
+			vr.fail(inst, kw("maxContains"), "must contain at most %d matching items, found %d", n.maxContains, count)
+		}
+	}
+}
+
+func (n *node) validateObject(x map[string]interface{}, inst string, vr *validator) {
+	kw := func(name string) string { return n.location + "/" + name }
+	if n.maxProperties >= 0 && len(x) > n.maxProperties {
+		vr.fail(inst, kw("maxProperties"), "must have at most %d properties", n.maxProperties)
+	}
+	if len(x) < n.minProperties {
+		vr.fail(inst, kw("minProperties"), "must have at least %d properties", n.minProperties)
+	}
+	for _, name := range n.required {
+		if _, ok := x[name]; !ok {
+			vr.fail(inst+"/"+escapePointer(name), kw("required"), "is required")
+		}
+	}
+
+	names := make([]string, 0, len(x))
+	for name := range x {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, dep := range n.dependentRequired[name] {
+			if _, ok := x[dep]; !ok {
+				vr.fail(inst+"/"+escapePointer(dep), kw("dependentRequired/"+escapePointer(name)), "is required when %q is present", name)
+			}
+		}
+	}
+
+	for _, name := range names {
+		value := x[name]
+		propInst := inst + "/" + escapePointer(name)
+		if n.propertyNames != nil {
+			sub := &validator{depth: vr.depth}
+			n.propertyNames.validate(name, propInst, sub)
+			for _, violation := range sub.violations {
+				violation.Message = "property name " + violation.Message
+				vr.violations = append(vr.violations, violation)
+			}
+		}
+
+		evaluated := false
+		if sub, ok := n.properties[name]; ok {
+			sub.validate(value, propInst, vr)
+			evaluated = true
+		}
+		for _, p := range n.patternProperties {
+			if p.re.MatchString(name) {
+				p.node.validate(value, propInst, vr)
+				evaluated = true
+			}
+		}
+		if !evaluated && n.additionalProperties != nil {
+			n.additionalProperties.validate(value, propInst, vr)
+		}
+		if sub, ok := n.dependentSchemas[name]; ok {
+			sub.validate(x, inst, vr)
+		}
+	}
+}
+
+// typeOf returns the JSON type of a decoded value.
+func typeOf(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if r, ok := new(big.Rat).SetString(string(x)); ok && r.IsInt() {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func hasType(v interface{}, types []string) bool {
+	actual := typeOf(v)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// equal reports whether two decoded JSON values are equal, comparing
+// numbers by value.
+func equal(a, b interface{}) bool {
+	switch x := a.(type) {
+	case json.Number:
+		y, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		rx, okx := new(big.Rat).SetString(string(x))
+		ry, oky := new(big.Rat).SetString(string(y))
+		return okx && oky && rx.Cmp(ry) == 0
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, xv := range x {
+			yv, ok := y[k]
+			if !ok || !equal(xv, yv) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+func formatValue(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+func formatValues(values []interface{}) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = formatValue(v)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,130 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate_Keywords(t *testing.T) {
+	for _, tc := range []struct {
+		schema  string
+		valid   []string
+		invalid map[string]string // instance -> first violation
+	}{
+		{`true`, []string{`1`, `null`}, nil},
+		{`false`, nil, map[string]string{`1`: "(root): is not allowed"}},
+		{`{"type": ["string", "null"]}`, []string{`"a"`, `null`}, map[string]string{
+			`1`: "(root): must be one of types string, null, got integer",
+		}},
+		{`{"type": "integer"}`, []string{`1`, `1.0`, `1e2`, `-0`}, map[string]string{
+			`1.5`: "(root): must be of type integer, got number",
+			`"1"`: "(root): must be of type integer, got string",
+		}},
+		{`{"type": "number"}`, []string{`1`, `1.5`}, map[string]string{`true`: "(root): must be of type number, got boolean"}},
+		{`{"enum": ["a", 1, {"b": [null]}]}`, []string{`"a"`, `1.0`, `{"b":[null]}`}, map[string]string{
+			`"b"`:            `(root): must be one of "a", 1, {"b":[null]}`,
+			`{"b":[null,1]}`: `(root): must be one of "a", 1, {"b":[null]}`,
+		}},
+		{`{"const": {"a": 1}}`, []string{`{"a":1}`}, map[string]string{`{"a":2}`: `(root): must be {"a":1}`}},
+		{`{"multipleOf": 0.1}`, []string{`0.3`, `10`, `"x"`}, map[string]string{`0.35`: "(root): must be a multiple of 0.1"}},
+		{`{"minimum": 1, "maximum": 3}`, []string{`1`, `3`}, map[string]string{
+			`0.999`: "(root): must be >= 1",
+			`3.001`: "(root): must be <= 3",
+		}},
+		{`{"minimum": 0}`, []string{`1e1000`, `"x"`}, map[string]string{
+			`-1e99999999`: "(root): cannot be checked against minimum 0: number out of range",
+		}},
+		{`{"exclusiveMinimum": 1, "exclusiveMaximum": 3}`, []string{`2`}, map[string]string{
+			`1`: "(root): must be > 1",
+			`3`: "(root): must be < 3",
+		}},
+		{`{"minLength": 2, "maxLength": 3}`, []string{`"ab"`, `"äöü"`, `1`}, map[string]string{
+			`"a"`:    "(root): must be at least 2 characters long",
+			`"abcd"`: "(root): must be at most 3 characters long",
+		}},
+		{`{"minItems": 1, "maxItems": 2, "uniqueItems": true}`, []string{`[1]`, `[1, "1"]`, `{}`}, map[string]string{
+			`[]`:                   "(root): must have at least 1 items",
+			`[1, 2, 3]`:            "(root): must have at most 2 items",
+			`[{"a":1}, {"a":1.0}]`: "(root): must not contain duplicates, items 0 and 1 are equal",
+		}},
+		{`{"prefixItems": [{"type": "string"}, {"type": "integer"}], "items": false}`, []string{`["a", 1]`, `["a"]`}, map[string]string{
+			`[1]`:          "/0: must be of type string, got integer",
+			`["a", 1, {}]`: "/2: is not allowed",
+		}},
+		{`{"contains": {"type": "integer"}, "minContains": 2, "maxContains": 3}`, []string{`[1, "a", 2]`}, map[string]string{
+			`[1, "a"]`:     "(root): must contain at least 2 matching items, found 1",
+			`[1, 2, 3, 4]`: "(root): must contain at most 3 matching items, found 4",
+		}},
+		{`{"contains": {"const": 0}}`, []string{`[0]`}, map[string]string{`[]`: "(root): must contain at least 1 matching items, found 0"}},
+		{`{"minProperties": 1, "maxProperties": 1}`, []string{`{"a":1}`}, map[string]string{
+			`{}`:            "(root): must have at least 1 properties",
+			`{"a":1,"b":2}`: "(root): must have at most 1 properties",
+		}},
+		{`{"required": ["a/b"], "dependentRequired": {"c": ["d"]}}`, []string{`{"a/b":1}`, `{"a/b":1,"c":1,"d":1}`}, map[string]string{
+			`{}`:              "/a~1b: is required",
+			`{"a/b":1,"c":1}`: `/d: is required when "c" is present`,
+		}},
+		{`{"patternProperties": {"^x-": {"type": "string"}}, "additionalProperties": {"type": "integer"}}`,
+			[]string{`{"x-a":"s","b":1}`}, map[string]string{
+				`{"x-a":1}`: "/x-a: must be of type string, got integer",
+				`{"b":"s"}`: "/b: must be of type integer, got string",
+			}},
+		{`{"propertyNames": {"maxLength": 2}}`, []string{`{"ab":1}`}, map[string]string{
+			`{"abc":1}`: "/abc: property name must be at most 2 characters long",
+		}},
+		{`{"dependentSchemas": {"card": {"required": ["billing"]}}}`, []string{`{}`, `{"card":1,"billing":1}`}, map[string]string{
+			`{"card":1}`: "/billing: is required",
+		}},
+		{`{"allOf": [{"minimum": 1}, {"maximum": 2}]}`, []string{`1.5`}, map[string]string{`3`: "(root): must be <= 2"}},
+		{`{"anyOf": [{"type": "string"}, {"minimum": 0}]}`, []string{`"a"`, `1`}, map[string]string{
+			`-1`: "(root): must match at least one schema in anyOf",
+		}},
+		{`{"oneOf": [{"type": "integer"}, {"minimum": 2}]}`, []string{`1`, `2.5`}, map[string]string{
+			`3`:    "(root): must match exactly one schema in oneOf, matched 0, 1",
+			`-0.5`: "(root): must match exactly one schema in oneOf, matched none",
+		}},
+		{`{"not": {"type": "null"}}`, []string{`1`}, map[string]string{`null`: "(root): must not match the schema in not"}},
+		{`{"if": {"properties": {"kind": {"const": "card"}}}, "then": {"required": ["last4"]}, "else": {"required": ["iban"]}}`,
+			[]string{`{"kind":"card","last4":"1234"}`, `{"kind":"sepa","iban":"DE"}`}, map[string]string{
+				`{"kind":"card"}`: "/last4: is required",
+				`{"kind":"sepa"}`: "/iban: is required",
+			}},
+		{`{"format": "email", "$comment": "annotations only", "title": "x"}`, []string{`"not an email"`}, nil},
+	} {
+		s, err := Compile([]byte(tc.schema))
+		if !assert.NoError(t, err, tc.schema) {
+			continue
+		}
+		for _, instance := range tc.valid {
+			assert.NoError(t, s.ValidateJSON([]byte(instance)), "%s against %s", instance, tc.schema)
+		}
+		for instance, want := range tc.invalid {
+			err := s.ValidateJSON([]byte(instance))
+			var verr *ValidationError
+			if assert.ErrorAs(t, err, &verr, "%s against %s", instance, tc.schema) {
+				assert.Equal(t, want, verr.Violations[0].String(), "%s against %s", instance, tc.schema)
+			}
+		}
+	}
+}
+
+func TestValidate_NumbersOutOfRange(t *testing.T) {
+	// big.Rat cannot represent these exponents, so the numeric keywords
+	// reject them instead of skipping them.
+	s := MustCompile(`{"properties": {"a": {"minimum": 0, "maximum": 10, "multipleOf": 2}, "b": {"type": "number"}}}`)
+
+	err := s.ValidateJSON([]byte(`{"a": -1e99999999}`))
+	var verr *ValidationError
+	if assert.ErrorAs(t, err, &verr) {
+		assert.Equal(t, []Violation{
+			{InstanceLocation: "/a", KeywordLocation: "/properties/a/multipleOf", Message: "cannot be checked against multipleOf 2: number out of range"},
+			{InstanceLocation: "/a", KeywordLocation: "/properties/a/maximum", Message: "cannot be checked against maximum 10: number out of range"},
+			{InstanceLocation: "/a", KeywordLocation: "/properties/a/minimum", Message: "cannot be checked against minimum 0: number out of range"},
+		}, verr.Violations)
+	}
+	assert.Error(t, s.ValidateJSON([]byte(`{"a": 1e-99999999}`)))
+
+	// Without numeric keywords there is nothing to compare.
+	assert.NoError(t, s.ValidateJSON([]byte(`{"b": 1e99999999}`)))
+}
@@ -0,0 +1,131 @@
+package vartiq
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Headers carrying the message ID and timestamp covered by a timestamped
+// signature.
+const (
+	MessageIDHeader = "X-Vartiq-Id"
+	TimestampHeader = "X-Vartiq-Timestamp"
+)
+
+// DefaultSignatureTolerance is how far a signature's timestamp may be from
+// the current time when VerifyOptions.Tolerance is zero.
+const DefaultSignatureTolerance = 5 * time.Minute
+
+var (
+	// ErrSignatureInvalid is matched by errors for signatures that are
+	// missing, malformed or do not match the payload.
+	ErrSignatureInvalid = errors.New("vartiq: invalid signature")
+	// ErrSignatureExpired is returned for a valid signature whose timestamp
+	// is older than the tolerance allows, e.g. a replayed request.
+	ErrSignatureExpired = errors.New("vartiq: signature expired")
+	// ErrSignatureFuture is returned for a valid signature whose timestamp
+	// is further in the future than the tolerance allows.
+	ErrSignatureFuture = errors.New("vartiq: signature timestamp is in the future")
+)
+
+// SignWithTimestamp returns the hex HMAC-SHA256 of "messageID.timestamp.payload",
+// where timestamp is in Unix seconds, as verified by Client.VerifyWithOptions.
+func SignWithTimestamp(messageID string, timestamp time.Time, payload []byte, secret string) string {
+	return hex.EncodeToString(signedContent(messageID, strconv.FormatInt(timestamp.Unix(), 10), payload, []byte(secret)))
+}
+
+// signedContent returns the HMAC-SHA256 of "messageID.timestamp.payload".
+func signedContent(messageID, timestamp string, payload, key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(messageID))
+	mac.Write([]byte("."))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// VerifyOptions holds the signed message ID and timestamp of a webhook
+// request, and how strictly the timestamp is checked.
+type VerifyOptions struct {
+	// MessageID is the value of the MessageIDHeader header, or of
+	// StandardIDHeader for VerifyStandard.
+	MessageID string
+	// Timestamp is the value of the TimestampHeader header, or of
+	// StandardTimestampHeader for VerifyStandard, in Unix seconds.
+	Timestamp string
+	// Tolerance is how far the timestamp may be from the current time in
+	// either direction. Zero means DefaultSignatureTolerance.
+	Tolerance time.Duration
+	// Now returns the current time. Nil means time.Now.
+	Now func() time.Time
+}
+
+// VerifyWithOptions checks a timestamped webhook signature, which covers the
+// message ID and timestamp as well as the payload, and rejects requests
+// signed outside the tolerance window so that captured requests cannot be
+// replayed later. It returns the payload bytes if the signature is valid.
+//
+// The error matches ErrSignatureInvalid when the signature is missing or
+// does not match, ErrSignatureExpired when the timestamp is too old and
+// ErrSignatureFuture when it is too far ahead.
+func (c *Client) VerifyWithOptions(payload []byte, signature, secret string, opts VerifyOptions) ([]byte, error) {
+	if signature == "" {
+		return nil, fmt.Errorf("%w: signature header is missing", ErrSignatureInvalid)
+	}
+	signedAt, err := opts.signedAt()
+	if err != nil {
+		return nil, err
+	}
+
+	receivedSignature, err := hex.DecodeString(signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decode signature: %v", ErrSignatureInvalid, err)
+	}
+	expectedSignature := signedContent(opts.MessageID, opts.Timestamp, payload, []byte(secret))
+	if subtle.ConstantTimeCompare(receivedSignature, expectedSignature) != 1 {
+		return nil, fmt.Errorf("%w: signature verification failed", ErrSignatureInvalid)
+	}
+
+	if err := opts.checkTimestamp(signedAt); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// signedAt checks that the message ID is present and parses the timestamp.
+func (o VerifyOptions) signedAt() (time.Time, error) {
+	if o.MessageID == "" {
+		return time.Time{}, fmt.Errorf("%w: message ID is missing", ErrSignatureInvalid)
+	}
+	seconds, err := strconv.ParseInt(o.Timestamp, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid timestamp %q", ErrSignatureInvalid, o.Timestamp)
+	}
+	return time.Unix(seconds, 0), nil
+}
+
+// checkTimestamp rejects a signature made outside the tolerance window.
+func (o VerifyOptions) checkTimestamp(signedAt time.Time) error {
+	tolerance := o.Tolerance
+	if tolerance == 0 {
+		tolerance = DefaultSignatureTolerance
+	}
+	now := time.Now
+	if o.Now != nil {
+		now = o.Now
+	}
+	switch age := now().Sub(signedAt); {
+	case age > tolerance:
+		return fmt.Errorf("%w: signed at %s, %s ago", ErrSignatureExpired, signedAt.UTC().Format(time.RFC3339), age.Truncate(time.Second))
+	case age < -tolerance:
+		return fmt.Errorf("%w: signed at %s, %s ahead", ErrSignatureFuture, signedAt.UTC().Format(time.RFC3339), (-age).Truncate(time.Second))
+	}
+	return nil
+}
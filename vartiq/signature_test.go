@@ -0,0 +1,172 @@
+package vartiq
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignWithTimestamp(t *testing.T) {
+	signedAt := time.Unix(1700000000, 0)
+	sig := SignWithTimestamp("msg_1", signedAt, []byte(`{"a":1}`), "secret")
+
+	assert.Len(t, sig, 64)
+	assert.Equal(t, sig, SignWithTimestamp("msg_1", signedAt, []byte(`{"a":1}`), "secret"))
+	assert.NotEqual(t, sig, SignWithTimestamp("msg_2", signedAt, []byte(`{"a":1}`), "secret"))
+	assert.NotEqual(t, sig, SignWithTimestamp("msg_1", signedAt.Add(time.Second), []byte(`{"a":1}`), "secret"))
+	assert.NotEqual(t, sig, SignWithTimestamp("msg_1", signedAt, []byte(`{"a":2}`), "secret"))
+	// The body-only signature does not verify as a timestamped one.
+	_, err := New("test-key").Verify([]byte(`{"a":1}`), sig, "secret")
+	assert.ErrorIs(t, err, ErrSignatureInvalid)
+}
+
+func TestClient_VerifyWithOptions(t *testing.T) {
+	client := New("test-key")
+	secret := "testsecret"
+	payload := []byte("testpayload")
+	signedAt := time.Unix(1700000000, 0)
+	timestamp := strconv.FormatInt(signedAt.Unix(), 10)
+	signature := SignWithTimestamp("msg_1", signedAt, payload, secret)
+	at := func(d time.Duration) func() time.Time {
+		return func() time.Time { return signedAt.Add(d) }
+	}
+
+	tests := []struct {
+		name      string
+		payload   []byte
+		signature string
+		secret    string
+		opts      VerifyOptions
+		wantErr   error
+		wantMsg   string
+	}{
+		{
+			name:    "Valid signature",
+			payload: payload, signature: signature, secret: secret,
+			opts: VerifyOptions{MessageID: "msg_1", Timestamp: timestamp, Now: at(time.Minute)},
+		},
+		{
+			name:    "Within tolerance in the future",
+			payload: payload, signature: signature, secret: secret,
+			opts: VerifyOptions{MessageID: "msg_1", Timestamp: timestamp, Now: at(-4 * time.Minute)},
+		},
+		{
+			name:    "Expired",
+			payload: payload, signature: signature, secret: secret,
+			opts:    VerifyOptions{MessageID: "msg_1", Timestamp: timestamp, Now: at(6 * time.Minute)},
+			wantErr: ErrSignatureExpired,
+			wantMsg: "vartiq: signature expired: signed at 2023-11-14T22:13:20Z, 6m0s ago",
+		},
+		{
+			name:    "Future dated",
+			payload: payload, signature: signature, secret: secret,
+			opts:    VerifyOptions{MessageID: "msg_1", Timestamp: timestamp, Now: at(-6 * time.Minute)},
+			wantErr: ErrSignatureFuture,
+			wantMsg: "vartiq: signature timestamp is in the future: signed at 2023-11-14T22:13:20Z, 6m0s ahead",
+		},
+		{
+			name:    "Custom tolerance",
+			payload: payload, signature: signature, secret: secret,
+			opts:    VerifyOptions{MessageID: "msg_1", Timestamp: timestamp, Tolerance: 30 * time.Second, Now: at(time.Minute)},
+			wantErr: ErrSignatureExpired,
+		},
+		{
+			name:    "Missing signature",
+			payload: payload, signature: "", secret: secret,
+			opts:    VerifyOptions{MessageID: "msg_1", Timestamp: timestamp, Now: at(0)},
+			wantErr: ErrSignatureInvalid,
+			wantMsg: "vartiq: invalid signature: signature header is missing",
+		},
+		{
+			name:    "Missing message ID",
+			payload: payload, signature: signature, secret: secret,
+			opts:    VerifyOptions{Timestamp: timestamp, Now: at(0)},
+			wantErr: ErrSignatureInvalid,
+			wantMsg: "vartiq: invalid signature: message ID is missing",
+		},
+		{
+			name:    "Invalid timestamp",
+			payload: payload, signature: signature, secret: secret,
+			opts:    VerifyOptions{MessageID: "msg_1", Timestamp: "yesterday", Now: at(0)},
+			wantErr: ErrSignatureInvalid,
+			wantMsg: `vartiq: invalid signature: invalid timestamp "yesterday"`,
+		},
+		{
+			name:    "Invalid signature format",
+			payload: payload, signature: "not-a-hex-string", secret: secret,
+			opts:    VerifyOptions{MessageID: "msg_1", Timestamp: timestamp, Now: at(0)},
+			wantErr: ErrSignatureInvalid,
+			wantMsg: "vartiq: invalid signature: failed to decode signature: encoding/hex: invalid byte: U+006E 'n'",
+		},
+		{
+			name:    "Mismatched secret",
+			payload: payload, signature: signature, secret: "wrongsecret",
+			opts:    VerifyOptions{MessageID: "msg_1", Timestamp: timestamp, Now: at(0)},
+			wantErr: ErrSignatureInvalid,
+			wantMsg: "vartiq: invalid signature: signature verification failed",
+		},
+		{
+			name:    "Replayed with another message ID",
+			payload: payload, signature: signature, secret: secret,
+			opts:    VerifyOptions{MessageID: "msg_2", Timestamp: timestamp, Now: at(0)},
+			wantErr: ErrSignatureInvalid,
+		},
+		{
+			name:    "Timestamp moved forward",
+			payload: payload, signature: signature, secret: secret,
+			opts:    VerifyOptions{MessageID: "msg_1", Timestamp: strconv.FormatInt(signedAt.Unix()+600, 10), Now: at(10 * time.Minute)},
+			wantErr: ErrSignatureInvalid,
+		},
+		{
+			name:    "Bad signature takes precedence over expiry",
+			payload: []byte("tampered"), signature: signature, secret: secret,
+			opts:    VerifyOptions{MessageID: "msg_1", Timestamp: timestamp, Now: at(time.Hour)},
+			wantErr: ErrSignatureInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verified, err := client.VerifyWithOptions(tt.payload, tt.signature, tt.secret, tt.opts)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.payload, verified)
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+			for _, other := range []error{ErrSignatureInvalid, ErrSignatureExpired, ErrSignatureFuture} {
+				if other != tt.wantErr {
+					assert.False(t, errors.Is(err, other), "error also matches %v", other)
+				}
+			}
+			if tt.wantMsg != "" {
+				assert.EqualError(t, err, tt.wantMsg)
+			}
+			assert.Nil(t, verified)
+		})
+	}
+}
+
+func TestClient_VerifyWithOptions_DefaultClock(t *testing.T) {
+	client := New("test-key")
+	payload := []byte("testpayload")
+
+	now := time.Now()
+	sig := SignWithTimestamp("msg_1", now, payload, "secret")
+	_, err := client.VerifyWithOptions(payload, sig, "secret", VerifyOptions{
+		MessageID: "msg_1",
+		Timestamp: strconv.FormatInt(now.Unix(), 10),
+	})
+	assert.NoError(t, err)
+
+	old := time.Now().Add(-time.Hour)
+	sig = SignWithTimestamp("msg_1", old, payload, "secret")
+	_, err = client.VerifyWithOptions(payload, sig, "secret", VerifyOptions{
+		MessageID: "msg_1",
+		Timestamp: strconv.FormatInt(old.Unix(), 10),
+	})
+	assert.ErrorIs(t, err, ErrSignatureExpired)
+}
@@ -0,0 +1,97 @@
+package vartiq
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers of the Standard Webhooks specification
+// (https://www.standardwebhooks.com), carrying the message ID, the signing
+// time in Unix seconds and the space-separated signatures.
+const (
+	StandardIDHeader        = "webhook-id"
+	StandardTimestampHeader = "webhook-timestamp"
+	StandardSignatureHeader = "webhook-signature"
+)
+
+// standardSecretPrefix marks a Standard Webhooks secret; the rest is the
+// base64 encoded signing key.
+const standardSecretPrefix = "whsec_"
+
+// standardKey decodes a Standard Webhooks secret, with or without its whsec_
+// prefix, into the HMAC key.
+func standardKey(secret string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(secret, standardSecretPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("vartiq: invalid Standard Webhooks secret: %w", err)
+	}
+	return key, nil
+}
+
+// SignStandard signs a payload as specified by Standard Webhooks, returning
+// the webhook-signature header value "v1,<base64 HMAC-SHA256>" over
+// "messageID.timestamp.payload". secret is a whsec_ secret, whose base64
+// encoded key is used for the HMAC.
+func SignStandard(messageID string, timestamp time.Time, payload []byte, secret string) (string, error) {
+	key, err := standardKey(secret)
+	if err != nil {
+		return "", err
+	}
+	mac := signedContent(messageID, strconv.FormatInt(timestamp.Unix(), 10), payload, key)
+	return "v1," + base64.StdEncoding.EncodeToString(mac), nil
+}
+
+// VerifyStandard checks a webhook signed as specified by Standard Webhooks,
+// so that senders using any Standard Webhooks library can be verified.
+// signature is the webhook-signature header, which may list several
+// space-separated signatures, e.g. during secret rotation; the payload is
+// accepted if any v1 signature matches, and signatures of other versions
+// are ignored. opts carries the webhook-id and webhook-timestamp headers and
+// the tolerance window. It returns the payload bytes if the signature is
+// valid.
+//
+// Errors match ErrSignatureInvalid, ErrSignatureExpired or
+// ErrSignatureFuture as for VerifyWithOptions. An invalid secret is reported
+// as an error matching none of them.
+func (c *Client) VerifyStandard(payload []byte, signature, secret string, opts VerifyOptions) ([]byte, error) {
+	key, err := standardKey(secret)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(signature) == "" {
+		return nil, fmt.Errorf("%w: signature header is missing", ErrSignatureInvalid)
+	}
+	signedAt, err := opts.signedAt()
+	if err != nil {
+		return nil, err
+	}
+
+	expectedSignature := signedContent(opts.MessageID, opts.Timestamp, payload, key)
+	matched := false
+	for _, versioned := range strings.Fields(signature) {
+		version, encoded, ok := strings.Cut(versioned, ",")
+		if !ok || version != "v1" {
+			continue
+		}
+		receivedSignature, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			continue
+		}
+		if subtle.ConstantTimeCompare(receivedSignature, expectedSignature) == 1 {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return nil, fmt.Errorf("%w: no matching v1 signature", ErrSignatureInvalid)
+	}
+
+	if err := opts.checkTimestamp(signedAt); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
@@ -0,0 +1,158 @@
+package vartiq
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The test vector published with the Standard Webhooks reference libraries.
+const (
+	standardSecret    = "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw"
+	standardMessageID = "msg_p5jXN8AQM9LWM0D4loKWxJek"
+	standardTimestamp = "1614265330"
+	standardSignature = "v1,g0hM9SsE+OTPJTGt/tmIKtSyZlE3uFJELVlNIOLJ1OE="
+)
+
+var standardPayload = []byte(`{"test": 2432232314}`)
+
+func TestSignStandard(t *testing.T) {
+	sig, err := SignStandard(standardMessageID, time.Unix(1614265330, 0), standardPayload, standardSecret)
+	require.NoError(t, err)
+	assert.Equal(t, standardSignature, sig)
+
+	// The whsec_ prefix is optional.
+	sig, err = SignStandard(standardMessageID, time.Unix(1614265330, 0), standardPayload, "MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw")
+	require.NoError(t, err)
+	assert.Equal(t, standardSignature, sig)
+
+	_, err = SignStandard(standardMessageID, time.Unix(1614265330, 0), standardPayload, "whsec_not base64!")
+	assert.ErrorContains(t, err, "vartiq: invalid Standard Webhooks secret")
+}
+
+func TestClient_VerifyStandard(t *testing.T) {
+	client := New("test-key")
+	signedAt := time.Unix(1614265330, 0)
+	at := func(d time.Duration) func() time.Time {
+		return func() time.Time { return signedAt.Add(d) }
+	}
+	opts := func(now func() time.Time) VerifyOptions {
+		return VerifyOptions{MessageID: standardMessageID, Timestamp: standardTimestamp, Now: now}
+	}
+	otherKey, err := SignStandard(standardMessageID, signedAt, standardPayload, "whsec_c2VjcmV0LWtleS1vbmU=")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		payload   []byte
+		signature string
+		secret    string
+		opts      VerifyOptions
+		wantErr   error
+		wantMsg   string
+	}{
+		{
+			name:    "Valid signature",
+			payload: standardPayload, signature: standardSignature, secret: standardSecret,
+			opts: opts(at(time.Minute)),
+		},
+		{
+			name:    "Multiple signatures",
+			payload: standardPayload, signature: otherKey + " v1a,ZmFrZQ== v1,!!! " + standardSignature, secret: standardSecret,
+			opts: opts(at(0)),
+		},
+		{
+			name:    "Secret without prefix",
+			payload: standardPayload, signature: standardSignature, secret: "MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw",
+			opts: opts(at(0)),
+		},
+		{
+			name:    "Expired",
+			payload: standardPayload, signature: standardSignature, secret: standardSecret,
+			opts:    opts(at(10 * time.Minute)),
+			wantErr: ErrSignatureExpired,
+		},
+		{
+			name:    "Future dated",
+			payload: standardPayload, signature: standardSignature, secret: standardSecret,
+			opts:    opts(at(-10 * time.Minute)),
+			wantErr: ErrSignatureFuture,
+		},
+		{
+			name:    "Tampered payload",
+			payload: []byte(`{"test": 1}`), signature: standardSignature, secret: standardSecret,
+			opts:    opts(at(0)),
+			wantErr: ErrSignatureInvalid,
+			wantMsg: "vartiq: invalid signature: no matching v1 signature",
+		},
+		{
+			name:    "Only other versions",
+			payload: standardPayload, signature: "v2," + standardSignature[3:], secret: standardSecret,
+			opts:    opts(at(0)),
+			wantErr: ErrSignatureInvalid,
+		},
+		{
+			name:    "Hex signature",
+			payload: standardPayload, signature: SignWithTimestamp(standardMessageID, signedAt, standardPayload, standardSecret), secret: standardSecret,
+			opts:    opts(at(0)),
+			wantErr: ErrSignatureInvalid,
+		},
+		{
+			name:    "Wrong secret",
+			payload: standardPayload, signature: otherKey, secret: standardSecret,
+			opts:    opts(at(0)),
+			wantErr: ErrSignatureInvalid,
+		},
+		{
+			name:    "Missing signature",
+			payload: standardPayload, signature: " ", secret: standardSecret,
+			opts:    opts(at(0)),
+			wantErr: ErrSignatureInvalid,
+			wantMsg: "vartiq: invalid signature: signature header is missing",
+		},
+		{
+			name:    "Missing message ID",
+			payload: standardPayload, signature: standardSignature, secret: standardSecret,
+			opts:    VerifyOptions{Timestamp: standardTimestamp, Now: at(0)},
+			wantErr: ErrSignatureInvalid,
+			wantMsg: "vartiq: invalid signature: message ID is missing",
+		},
+		{
+			name:    "Invalid timestamp",
+			payload: standardPayload, signature: standardSignature, secret: standardSecret,
+			opts:    VerifyOptions{MessageID: standardMessageID, Timestamp: "", Now: at(0)},
+			wantErr: ErrSignatureInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verified, err := client.VerifyStandard(tt.payload, tt.signature, tt.secret, tt.opts)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.payload, verified)
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantMsg != "" {
+				assert.EqualError(t, err, tt.wantMsg)
+			}
+			assert.Nil(t, verified)
+		})
+	}
+}
+
+func TestClient_VerifyStandard_InvalidSecret(t *testing.T) {
+	_, err := New("test-key").VerifyStandard(standardPayload, standardSignature, "whsec_%%%", VerifyOptions{
+		MessageID: standardMessageID,
+		Timestamp: standardTimestamp,
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "vartiq: invalid Standard Webhooks secret")
+	for _, sentinel := range []error{ErrSignatureInvalid, ErrSignatureExpired, ErrSignatureFuture} {
+		assert.False(t, errors.Is(err, sentinel))
+	}
+}
@@ -0,0 +1,76 @@
+package vartiq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// TypedMessage is a WebhookMessage whose payload is decoded into T.
+type TypedMessage[T any] struct {
+	WebhookMessage
+	// Payload shadows WebhookMessage.Payload with the typed value.
+	Payload T
+}
+
+// SendTyped creates a webhook message from a typed payload and returns it
+// with the payload decoded back into T. With T set to json.RawMessage the
+// payload is sent and returned as is.
+// Example:
+//
+//	type InvoicePaid struct {
+//	    InvoiceID string `json:"invoiceId"`
+//	    Amount    int64  `json:"amount"`
+//	}
+//
+//	msg, err := vartiq.SendTyped(ctx, client, "APP_ID", InvoicePaid{InvoiceID: "inv_1", Amount: 4200})
+//	fmt.Println(msg.Data.Payload.InvoiceID)
+func SendTyped[T any](ctx context.Context, api API, appID string, payload T, opts ...RequestOption) (*Response[TypedMessage[T]], error) {
+	resp, err := api.WebhookMessages().Create(ctx, appID, payload, opts...)
+	if err != nil {
+		return nil, err
+	}
+	typed, err := DecodePayload[T](resp.Data)
+	if err != nil {
+		return nil, err
+	}
+	return &Response[TypedMessage[T]]{
+		Data:           TypedMessage[T]{WebhookMessage: resp.Data, Payload: typed},
+		Message:        resp.Message,
+		Success:        resp.Success,
+		StatusCode:     resp.StatusCode,
+		Header:         resp.Header,
+		RequestID:      resp.RequestID,
+		IdempotencyKey: resp.IdempotencyKey,
+		Replayed:       resp.Replayed,
+	}, nil
+}
+
+// DecodePayload decodes the payload of msg into T. It reads RawPayload when
+// set, and otherwise re-encodes Payload. Decoding into json.RawMessage
+// returns RawPayload without copying.
+// Example:
+//
+//	messages, err := client.WebhookMessage.List(ctx, "APP_ID", nil)
+//	for _, m := range messages.Data {
+//	    event, err := vartiq.DecodePayload[InvoicePaid](m)
+//	    ...
+//	}
+func DecodePayload[T any](msg WebhookMessage) (T, error) {
+	var v T
+	raw := msg.RawPayload
+	if raw == nil {
+		var err error
+		if raw, err = json.Marshal(msg.Payload); err != nil {
+			return v, fmt.Errorf("failed to encode payload: %w", err)
+		}
+	}
+	if rm, ok := interface{}(&v).(*json.RawMessage); ok {
+		*rm = raw
+		return v, nil
+	}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return v, fmt.Errorf("failed to decode payload into %T: %w", v, err)
+	}
+	return v, nil
+}
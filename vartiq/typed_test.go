@@ -0,0 +1,61 @@
+package vartiq
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type invoicePaid struct {
+	InvoiceID string `json:"invoiceId"`
+	Amount    int64  `json:"amount"`
+}
+
+func TestSendTyped(t *testing.T) {
+	client := newTestServer(t, http.StatusCreated, `{"success":true,"message":"created","data":{"webhookMessages":[
+		{"id":"m1","payload":"{\"invoiceId\":\"inv_1\",\"amount\":4200}"}
+	]}}`, http.Header{requestIDHeader: {"req-1"}})
+
+	resp, err := SendTyped(context.Background(), client, "app-1", invoicePaid{InvoiceID: "inv_1", Amount: 4200})
+	require.NoError(t, err)
+	assert.Equal(t, invoicePaid{InvoiceID: "inv_1", Amount: 4200}, resp.Data.Payload)
+	assert.Equal(t, "m1", resp.Data.ID)
+	assert.Equal(t, "app-1", resp.Data.AppID)
+	assert.Equal(t, "req-1", resp.RequestID)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.NotEmpty(t, resp.IdempotencyKey)
+
+	raw, err := SendTyped(context.Background(), client, "app-1", json.RawMessage(`{"invoiceId":"inv_1","amount":4200}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"invoiceId":"inv_1","amount":4200}`, string(raw.Data.Payload))
+}
+
+func TestSendTyped_Error(t *testing.T) {
+	client := newTestServer(t, http.StatusNotFound, `{"success":false,"message":"App not found"}`, nil)
+	_, err := SendTyped(context.Background(), client, "missing", invoicePaid{})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestDecodePayload(t *testing.T) {
+	raw := json.RawMessage(`{"invoiceId":"inv_1","amount":4200}`)
+
+	got, err := DecodePayload[invoicePaid](WebhookMessage{RawPayload: raw})
+	require.NoError(t, err)
+	assert.Equal(t, invoicePaid{InvoiceID: "inv_1", Amount: 4200}, got)
+
+	// Without RawPayload the decoded Payload is re-encoded.
+	got, err = DecodePayload[invoicePaid](WebhookMessage{Payload: map[string]interface{}{"invoiceId": "inv_2"}})
+	require.NoError(t, err)
+	assert.Equal(t, invoicePaid{InvoiceID: "inv_2"}, got)
+
+	rm, err := DecodePayload[json.RawMessage](WebhookMessage{RawPayload: raw})
+	require.NoError(t, err)
+	assert.Equal(t, &raw[0], &rm[0], "RawMessage is returned without copying")
+
+	_, err = DecodePayload[invoicePaid](WebhookMessage{RawPayload: json.RawMessage(`"not an object"`)})
+	assert.ErrorContains(t, err, "failed to decode payload into vartiq.invoicePaid")
+}
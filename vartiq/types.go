@@ -1,10 +1,153 @@
 package vartiq
 
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Sentinel errors classifying API and client-side failures. Match them with
+// errors.Is, e.g.
+//
+//	if errors.Is(err, vartiq.ErrNotFound) { ... }
+var (
+	ErrValidation   = errors.New("vartiq: validation failed")
+	ErrUnauthorized = errors.New("vartiq: unauthorized")
+	ErrForbidden    = errors.New("vartiq: forbidden")
+	ErrNotFound     = errors.New("vartiq: not found")
+	ErrConflict     = errors.New("vartiq: conflict")
+	ErrRateLimited  = errors.New("vartiq: rate limited")
+	ErrServer       = errors.New("vartiq: server error")
+)
+
+// APIError is returned when the Vartiq API reports a failure.
+// Code holds the HTTP status code; Fields carries per-field details
+// when the API rejected the request body.
 type APIError struct {
-	Message string `json:"message"`
-	Code    int    `json:"code,omitempty"`
+	Message   string       `json:"message"`
+	Code      int          `json:"code,omitempty"`
+	RequestID string       `json:"requestId,omitempty"`
+	Fields    []FieldError `json:"errors,omitempty"`
 }
 
 func (e *APIError) Error() string {
 	return e.Message
 }
+
+// Unwrap maps the HTTP status onto the package sentinels. Validation
+// failures unwrap to a *ValidationError so callers can inspect the fields.
+func (e *APIError) Unwrap() error {
+	switch {
+	case e.Code == http.StatusBadRequest || e.Code == http.StatusUnprocessableEntity:
+		return &ValidationError{Message: e.Message, Fields: e.Fields}
+	case e.Code == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case e.Code == http.StatusForbidden:
+		return ErrForbidden
+	case e.Code == http.StatusNotFound:
+		return ErrNotFound
+	case e.Code == http.StatusConflict:
+		return ErrConflict
+	case e.Code == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.Code >= http.StatusInternalServerError:
+		return ErrServer
+	}
+	return nil
+}
+
+// FieldError describes a single invalid request field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError reports a request rejected because of invalid fields,
+// either by client-side checks before sending or by the API.
+// It matches ErrValidation with errors.Is.
+type ValidationError struct {
+	Message string
+	Fields  []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Field+": "+f.Message)
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// Ptr returns a pointer to v, for filling optional request fields.
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+// deref returns the value p points to, or the zero value when p is nil.
+func deref[T any](p *T) T {
+	if p == nil {
+		var zero T
+		return zero
+	}
+	return *p
+}
+
+// requestIDHeader is the response header carrying the server-side request ID.
+const requestIDHeader = "X-Request-Id"
+
+// checkResponse returns nil for 2xx responses and an *APIError otherwise,
+// decoding the server message and request ID from the error body when present.
+func checkResponse(resp *resty.Response) error {
+	if resp.IsSuccess() {
+		return nil
+	}
+
+	apiErr := &APIError{
+		Code:      resp.StatusCode(),
+		RequestID: resp.Header().Get(requestIDHeader),
+	}
+
+	var body struct {
+		Message   string       `json:"message"`
+		Error     string       `json:"error"`
+		RequestID string       `json:"requestId"`
+		Errors    []FieldError `json:"errors"`
+	}
+	if err := json.Unmarshal(resp.Body(), &body); err == nil {
+		apiErr.Message = body.Message
+		if apiErr.Message == "" {
+			apiErr.Message = body.Error
+		}
+		if apiErr.RequestID == "" {
+			apiErr.RequestID = body.RequestID
+		}
+		apiErr.Fields = body.Errors
+	} else {
+		apiErr.Message = strings.TrimSpace(string(resp.Body()))
+	}
+
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(apiErr.Code)
+	}
+	return apiErr
+}
+
+// unsuccessful builds the error for a 2xx response whose envelope reports
+// success=false.
+func unsuccessful(resp *resty.Response, message string) error {
+	return &APIError{
+		Message:   message,
+		Code:      resp.StatusCode(),
+		RequestID: resp.Header().Get(requestIDHeader),
+	}
+}
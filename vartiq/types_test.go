@@ -1,12 +1,155 @@
 package vartiq
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAPIError_Error(t *testing.T) {
 	err := &APIError{Message: "something went wrong", Code: 400}
 	assert.Equal(t, "something went wrong", err.Error())
 }
+
+func TestError_IsAPIError(t *testing.T) {
+	client := newTestServer(t, http.StatusNotFound, `{"success":false,"message":"Project not found"}`, nil)
+	_, err := client.Project.Get(context.Background(), "missing")
+
+	var legacy *Error
+	require.ErrorAs(t, err, &legacy)
+	assert.Equal(t, "Project not found", legacy.Message)
+	assert.Equal(t, "boom", (&Error{Message: "boom"}).Error())
+}
+
+func newTestServer(t *testing.T, status int, body string, header http.Header) *Client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for k, v := range header {
+			w.Header()[k] = v
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return NewClient("test-key", WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{}))
+}
+
+func TestCheckResponse_NonSuccess(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		header    http.Header
+		message   string
+		requestID string
+	}{
+		{
+			name:      "JSON message with request ID header",
+			status:    http.StatusNotFound,
+			body:      `{"message":"Project not found","success":false}`,
+			header:    http.Header{"X-Request-Id": {"req-123"}},
+			message:   "Project not found",
+			requestID: "req-123",
+		},
+		{
+			name:      "JSON error field with request ID in body",
+			status:    http.StatusBadRequest,
+			body:      `{"error":"name is required","requestId":"req-456"}`,
+			message:   "name is required",
+			requestID: "req-456",
+		},
+		{
+			name:    "Plain text body",
+			status:  http.StatusBadGateway,
+			body:    "upstream unavailable\n",
+			message: "upstream unavailable",
+		},
+		{
+			name:    "Empty body",
+			status:  http.StatusInternalServerError,
+			body:    "",
+			message: "Internal Server Error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestServer(t, tt.status, tt.body, tt.header)
+			_, err := client.Project.Get(context.Background(), "id")
+			require.Error(t, err)
+
+			var apiErr *APIError
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, tt.status, apiErr.Code)
+			assert.Equal(t, tt.message, apiErr.Message)
+			assert.Equal(t, tt.requestID, apiErr.RequestID)
+		})
+	}
+}
+
+func TestCheckResponse_DeleteFailure(t *testing.T) {
+	client := newTestServer(t, http.StatusForbidden, `{"message":"forbidden"}`, nil)
+	err := client.App.Delete(context.Background(), "id")
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusForbidden, apiErr.Code)
+}
+
+func TestCheckResponse_Success(t *testing.T) {
+	client := newTestServer(t, http.StatusOK, `{"data":{"id":"p1"},"message":"ok","success":true}`, nil)
+	resp, err := client.Project.Get(context.Background(), "p1")
+	require.NoError(t, err)
+	assert.Equal(t, "p1", resp.Data.ID)
+}
+
+func TestAPIError_Sentinels(t *testing.T) {
+	tests := []struct {
+		code     int
+		sentinel error
+	}{
+		{http.StatusBadRequest, ErrValidation},
+		{http.StatusUnprocessableEntity, ErrValidation},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrForbidden},
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusConflict, ErrConflict},
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusServiceUnavailable, ErrServer},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.code), func(t *testing.T) {
+			client := newTestServer(t, tt.code, `{"message":"failed"}`, nil)
+			err := client.Project.Delete(context.Background(), "id")
+			assert.ErrorIs(t, err, tt.sentinel)
+			if tt.sentinel != ErrNotFound {
+				assert.NotErrorIs(t, err, ErrNotFound)
+			}
+		})
+	}
+}
+
+func TestAPIError_ValidationFields(t *testing.T) {
+	client := newTestServer(t, http.StatusBadRequest,
+		`{"message":"invalid body","errors":[{"field":"name","message":"is required"}]}`, nil)
+	_, err := client.Project.Create(context.Background(), &CreateProjectRequest{})
+
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "invalid body", validationErr.Error())
+	assert.Equal(t, []FieldError{{Field: "name", Message: "is required"}}, validationErr.Fields)
+}
+
+func TestValidationError_Error(t *testing.T) {
+	err := &ValidationError{Fields: []FieldError{
+		{Field: "userName", Message: "is required"},
+		{Field: "password", Message: "is required"},
+	}}
+	assert.Equal(t, "validation failed: userName: is required; password: is required", err.Error())
+	assert.ErrorIs(t, err, ErrValidation)
+}
@@ -0,0 +1,289 @@
+package vartiq
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// defaultCompactEvery is the number of finished messages after which a WAL
+// rewrites its file.
+const defaultCompactEvery = 1024
+
+// walRecord is one line of the log: a message added, or a message finished.
+type walRecord struct {
+	Op      string          `json:"op"`
+	ID      uint64          `json:"id"`
+	AppID   string          `json:"app,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+const (
+	walAdd  = "add"
+	walDone = "done"
+)
+
+// WAL is an append-only file recording the messages a Producer has
+// accepted but not yet sent, so they survive a crash or restart. Pass it to
+// NewProducer with WithWAL.
+//
+// Each accepted message is written and synced to disk before Send returns;
+// sent messages are marked done, and the file is rewritten without them from
+// time to time. A message that was sent but not yet marked done when the
+// process stopped is sent again on the next start, so delivery is at least
+// once.
+type WAL struct {
+	path string
+
+	mu   sync.Mutex
+	file walFile
+	// size is the length of the file up to the end of the last complete
+	// record, where the next record is written.
+	size int64
+	// err is set once a failed write could not be undone; the file may end
+	// in a partial record, so nothing more is written to it.
+	err          error
+	nextID       uint64
+	pending      map[uint64]walRecord
+	finished     int
+	compactEvery int
+}
+
+// walFile is the part of *os.File a WAL uses, so tests can inject failures.
+type walFile interface {
+	io.ReadWriteSeeker
+	Truncate(size int64) error
+	Sync() error
+	Close() error
+}
+
+// OpenWAL opens the log at path, creating it if needed, and loads the
+// messages it holds that were not sent. A record cut short by a crash at the
+// end of the file is discarded.
+func OpenWAL(path string) (*WAL, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("vartiq: open wal: %w", err)
+	}
+	w := &WAL{path: path, file: file, pending: make(map[uint64]walRecord), compactEvery: defaultCompactEvery}
+	if err := w.load(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("vartiq: load wal %s: %w", path, err)
+	}
+	return w, nil
+}
+
+// load replays the file into w.pending and positions it for appending.
+func (w *WAL) load() error {
+	r := bufio.NewReader(w.file)
+	var offset int64
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			// Anything after the last newline is a partial write.
+			if len(line) > 0 {
+				if err := w.file.Truncate(offset); err != nil {
+					return err
+				}
+			}
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		var rec walRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			if _, peekErr := r.Peek(1); peekErr == io.EOF {
+				if err := w.file.Truncate(offset); err != nil {
+					return err
+				}
+				break
+			}
+			return fmt.Errorf("corrupt record at offset %d: %w", offset, err)
+		}
+		offset += int64(len(line))
+		w.size = offset
+
+		switch rec.Op {
+		case walAdd:
+			w.pending[rec.ID] = rec
+		case walDone:
+			delete(w.pending, rec.ID)
+			w.finished++
+		}
+		if rec.ID >= w.nextID {
+			w.nextID = rec.ID + 1
+		}
+	}
+	_, err := w.file.Seek(offset, io.SeekStart)
+	return err
+}
+
+// Pending returns the number of messages in the log not yet sent.
+func (w *WAL) Pending() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.pending)
+}
+
+// Close closes the file. Messages not yet sent stay in it.
+func (w *WAL) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// add records a message and syncs it to disk, returning its ID.
+func (w *WAL) add(appID string, payload interface{}) (uint64, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("vartiq: encode payload: %w", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	rec := walRecord{Op: walAdd, ID: w.nextID, AppID: appID, Payload: raw}
+	start := w.size
+	if err := w.write(rec); err != nil {
+		return 0, err
+	}
+	if err := w.file.Sync(); err != nil {
+		// The record may or may not be on disk; remove it so that a message
+		// reported as not accepted is not sent after a restart.
+		return 0, w.rollback(start, fmt.Errorf("vartiq: sync wal: %w", err))
+	}
+	w.nextID++
+	w.pending[rec.ID] = rec
+	return rec.ID, nil
+}
+
+// done marks a message as finished, compacting the file when enough
+// messages have finished. Losing a done record only causes a resend, so it
+// is not synced on its own.
+func (w *WAL) done(id uint64) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.pending[id]; !ok {
+		return nil
+	}
+	if err := w.write(walRecord{Op: walDone, ID: id}); err != nil {
+		return err
+	}
+	delete(w.pending, id)
+	w.finished++
+	if w.finished >= w.compactEvery {
+		return w.compact()
+	}
+	return nil
+}
+
+// unsent returns the pending records in the order they were added.
+func (w *WAL) unsent() []walRecord {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	recs := make([]walRecord, 0, len(w.pending))
+	for _, rec := range w.pending {
+		recs = append(recs, rec)
+	}
+	sort.Slice(recs, func(i, j int) bool { return recs[i].ID < recs[j].ID })
+	return recs
+}
+
+// write appends rec to the file. If only part of it is written, e.g. when
+// the disk is full, the file is cut back to the last complete record so
+// that later records can still be read. Callers hold w.mu.
+func (w *WAL) write(rec walRecord) error {
+	if w.file == nil {
+		return errors.New("vartiq: wal closed")
+	}
+	if w.err != nil {
+		return w.err
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	n, err := w.file.Write(append(line, '\n'))
+	if err != nil {
+		return w.rollback(w.size, fmt.Errorf("vartiq: write wal: %w", err))
+	}
+	w.size += int64(n)
+	return nil
+}
+
+// rollback truncates the file to size after err, so that it ends with a
+// complete record. If that fails too, the WAL refuses further writes.
+// Callers hold w.mu.
+func (w *WAL) rollback(size int64, err error) error {
+	if truncErr := w.file.Truncate(size); truncErr != nil {
+		w.err = fmt.Errorf("%w; wal unusable, truncate failed: %w", err, truncErr)
+		return w.err
+	}
+	if _, seekErr := w.file.Seek(size, io.SeekStart); seekErr != nil {
+		w.err = fmt.Errorf("%w; wal unusable, seek failed: %w", err, seekErr)
+		return w.err
+	}
+	w.size = size
+	return err
+}
+
+// compact replaces the file with one holding only the pending records. The
+// new file is synced before it is renamed over the old one, so a crash
+// leaves either the old or the new file in place. Callers hold w.mu.
+func (w *WAL) compact() error {
+	var buf bytes.Buffer
+	for _, rec := range w.pending {
+		line, err := json.Marshal(rec)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	tmp := w.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("vartiq: compact wal: %w", err)
+	}
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		file.Close()
+		return fmt.Errorf("vartiq: compact wal: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("vartiq: compact wal: %w", err)
+	}
+	if err := os.Rename(tmp, w.path); err != nil {
+		file.Close()
+		return fmt.Errorf("vartiq: compact wal: %w", err)
+	}
+	syncDir(filepath.Dir(w.path))
+
+	w.file.Close()
+	w.file = file
+	w.size = int64(buf.Len())
+	w.finished = 0
+	return nil
+}
+
+// syncDir makes a rename in dir durable where the platform supports it.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		d.Close()
+	}
+}
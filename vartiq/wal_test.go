@@ -0,0 +1,239 @@
+package vartiq
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWAL_ReplaysUnsentMessages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.wal")
+	w, err := OpenWAL(path)
+	require.NoError(t, err)
+
+	first, err := w.add("app-1", map[string]string{"n": "1"})
+	require.NoError(t, err)
+	_, err = w.add("app-2", "two")
+	require.NoError(t, err)
+	require.NoError(t, w.done(first))
+	assert.Equal(t, 1, w.Pending())
+	require.NoError(t, w.Close())
+
+	w, err = OpenWAL(path)
+	require.NoError(t, err)
+	defer w.Close()
+	recs := w.unsent()
+	require.Len(t, recs, 1)
+	assert.Equal(t, "app-2", recs[0].AppID)
+	assert.JSONEq(t, `"two"`, string(recs[0].Payload))
+
+	// IDs keep increasing across restarts.
+	id, err := w.add("app-3", 3)
+	require.NoError(t, err)
+	assert.Greater(t, id, recs[0].ID)
+}
+
+func TestWAL_DiscardsPartialTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.wal")
+	w, err := OpenWAL(path)
+	require.NoError(t, err)
+	_, err = w.add("app-1", 1)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"op":"add","id":1,"app":"app-1","payl`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	w, err = OpenWAL(path)
+	require.NoError(t, err)
+	assert.Equal(t, 1, w.Pending())
+
+	// New records follow the last complete one.
+	_, err = w.add("app-1", 2)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	w, err = OpenWAL(path)
+	require.NoError(t, err)
+	defer w.Close()
+	assert.Equal(t, 2, w.Pending())
+}
+
+func TestWAL_RejectsCorruptRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.wal")
+	require.NoError(t, os.WriteFile(path, []byte("garbage\n{\"op\":\"add\",\"id\":0}\n"), 0o600))
+	_, err := OpenWAL(path)
+	assert.ErrorContains(t, err, "corrupt record")
+}
+
+// faultyFile fails the next write after writing only part of it, or the
+// next sync, or every truncate.
+type faultyFile struct {
+	walFile
+	shortWrite   bool
+	failSync     bool
+	failTruncate bool
+}
+
+func (f *faultyFile) Write(p []byte) (int, error) {
+	if f.shortWrite {
+		f.shortWrite = false
+		n, _ := f.walFile.Write(p[:len(p)/2])
+		return n, syscall.ENOSPC
+	}
+	return f.walFile.Write(p)
+}
+
+func (f *faultyFile) Sync() error {
+	if f.failSync {
+		f.failSync = false
+		return syscall.EIO
+	}
+	return f.walFile.Sync()
+}
+
+func (f *faultyFile) Truncate(size int64) error {
+	if f.failTruncate {
+		return syscall.EIO
+	}
+	return f.walFile.Truncate(size)
+}
+
+func TestWAL_UndoesFailedWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.wal")
+	w, err := OpenWAL(path)
+	require.NoError(t, err)
+	faulty := &faultyFile{walFile: w.file}
+	w.file = faulty
+
+	_, err = w.add("app-1", "kept")
+	require.NoError(t, err)
+	faulty.shortWrite = true
+	_, err = w.add("app-1", "short write")
+	assert.ErrorIs(t, err, syscall.ENOSPC)
+	faulty.failSync = true
+	_, err = w.add("app-1", "failed sync")
+	assert.ErrorIs(t, err, syscall.EIO)
+	id, err := w.add("app-1", "after")
+	require.NoError(t, err)
+	require.NoError(t, w.done(id))
+	_, err = w.add("app-1", "last")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	w, err = OpenWAL(path)
+	require.NoError(t, err, "the file has no partial records in the middle")
+	defer w.Close()
+	var payloads []string
+	for _, rec := range w.unsent() {
+		payloads = append(payloads, string(rec.Payload))
+	}
+	assert.Equal(t, []string{`"kept"`, `"last"`}, payloads)
+}
+
+func TestWAL_StopsWritingWhenUndoFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.wal")
+	w, err := OpenWAL(path)
+	require.NoError(t, err)
+	faulty := &faultyFile{walFile: w.file, shortWrite: true, failTruncate: true}
+	w.file = faulty
+
+	_, err = w.add("app-1", "short write")
+	assert.ErrorIs(t, err, syscall.ENOSPC)
+	assert.ErrorContains(t, err, "wal unusable")
+	_, err = w.add("app-1", "next")
+	assert.ErrorContains(t, err, "wal unusable", "nothing is written after a partial record")
+	require.NoError(t, w.Close())
+}
+
+func TestWAL_Compacts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.wal")
+	w, err := OpenWAL(path)
+	require.NoError(t, err)
+	w.compactEvery = 10
+
+	var ids []uint64
+	for i := 0; i < 11; i++ {
+		id, err := w.add("app-1", i)
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+	before, err := os.Stat(path)
+	require.NoError(t, err)
+	for _, id := range ids[:10] {
+		require.NoError(t, w.done(id))
+	}
+	after, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Less(t, after.Size(), before.Size())
+
+	// Appending still works on the compacted file.
+	_, err = w.add("app-1", "late")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	w, err = OpenWAL(path)
+	require.NoError(t, err)
+	defer w.Close()
+	recs := w.unsent()
+	require.Len(t, recs, 2)
+	assert.JSONEq(t, `10`, string(recs[0].Payload))
+	assert.JSONEq(t, `"late"`, string(recs[1].Payload))
+}
+
+func TestProducer_WALSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.wal")
+
+	// The first run cannot reach the API and is shut down.
+	down := &batchRecorder{fn: func(_ int, payloads []interface{}) []error {
+		errs := make([]error, len(payloads))
+		for i := range errs {
+			errs[i] = &APIError{Code: 503, Message: "unavailable"}
+		}
+		return errs
+	}}
+	w, err := OpenWAL(path)
+	require.NoError(t, err)
+	reported := 0
+	p := NewProducer(down, WithWAL(w), WithRetryBackoff(time.Hour),
+		WithFailureHandler(func(ProducerFailure) { reported++ }))
+	require.NoError(t, p.Send(context.Background(), "app-1", map[string]int{"n": 1}))
+	require.NoError(t, p.Send(context.Background(), "app-1", map[string]int{"n": 2}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, p.Close(ctx), context.DeadlineExceeded)
+	assert.Zero(t, reported)
+
+	// The next run sends what the first one could not.
+	up := &batchRecorder{}
+	w, err = OpenWAL(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, w.Pending())
+	p = NewProducer(up, WithWAL(w))
+	require.NoError(t, p.Close(context.Background()))
+
+	var sent []string
+	for _, c := range up.sent() {
+		for _, payload := range c.payloads {
+			raw, ok := payload.(json.RawMessage)
+			require.True(t, ok)
+			sent = append(sent, string(raw))
+		}
+	}
+	assert.Equal(t, []string{`{"n":1}`, `{"n":2}`}, sent)
+
+	w, err = OpenWAL(path)
+	require.NoError(t, err)
+	defer w.Close()
+	assert.Zero(t, w.Pending())
+}
@@ -2,8 +2,6 @@ package vartiq
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
 )
 
@@ -39,6 +37,9 @@ type Webhook struct {
 	AuthMethod    *WebhookAuth `json:"authMethod,omitempty"`
 	CreatedAt     string       `json:"createdAt"`
 	UpdatedAt     string       `json:"updatedAt"`
+	// EventTypes lists the event types the webhook is subscribed to; it
+	// receives every message when empty.
+	EventTypes []string `json:"eventTypes,omitempty"`
 }
 
 type Header struct {
@@ -46,11 +47,18 @@ type Header struct {
 	Value string `json:"value"`
 }
 
+// CreateWebhookRequest is used for creating a webhook. Set Auth with
+// BasicAuth, APIKeyAuth or HMACAuth, or alternatively AuthMethod with the
+// matching credential fields below; not both.
 type CreateWebhookRequest struct {
-	URL           string   `json:"url"`
-	AppID         string   `json:"appId"`
-	CustomHeaders []Header `json:"customHeaders,omitempty"`
-	AuthMethod    string   `json:"authMethod,omitempty"`
+	URL           string            `json:"url"`
+	AppID         string            `json:"appId"`
+	CustomHeaders []Header          `json:"customHeaders,omitempty"`
+	Auth          WebhookAuthConfig `json:"-"`
+	AuthMethod    string            `json:"authMethod,omitempty"`
+	// EventTypes subscribes the webhook to messages of these registered
+	// event types only. Leave empty to receive every message of the app.
+	EventTypes []string `json:"eventTypes,omitempty"`
 	// Basic Auth
 	UserName string `json:"userName,omitempty"`
 	Password string `json:"password,omitempty"`
@@ -62,74 +70,203 @@ type CreateWebhookRequest struct {
 	HMACSecret string `json:"hmacSecret,omitempty"`
 }
 
-type WebhookResponse struct {
-	Data    Webhook `json:"data"`
-	Message string  `json:"message"`
-	Success bool    `json:"success"`
+// WebhookResponse is kept for compatibility; it is the same type as Response[Webhook].
+type WebhookResponse = Response[Webhook]
+
+// WebhookListResponse is kept for compatibility; it is the same type as Response[[]Webhook].
+type WebhookListResponse = Response[[]Webhook]
+
+// UpdateWebhookRequest is used for updating a webhook. Only non-nil fields
+// are sent; use Ptr to set them. Changing credentials requires AuthMethod
+// together with every field that method needs, as in CreateWebhookRequest.
+type UpdateWebhookRequest struct {
+	URL *string `json:"url,omitempty"`
+	// CustomHeaders replaces the webhook's custom headers; point it at an
+	// empty slice to remove them all.
+	CustomHeaders *[]Header `json:"customHeaders,omitempty"`
+	AuthMethod    *string   `json:"authMethod,omitempty"`
+	// Basic Auth
+	UserName *string `json:"userName,omitempty"`
+	Password *string `json:"password,omitempty"`
+	// API Key Auth
+	APIKey       *string `json:"apiKey,omitempty"`
+	APIKeyHeader *string `json:"apiKeyHeader,omitempty"`
+	// HMAC Auth
+	HMACHeader *string `json:"hmacHeader,omitempty"`
+	HMACSecret *string `json:"hmacSecret,omitempty"`
+	// EventTypes replaces the webhook's event type subscriptions; point it
+	// at an empty slice to receive every message again.
+	EventTypes *[]string `json:"eventTypes,omitempty"`
 }
 
-type WebhookListResponse struct {
-	Data    []Webhook `json:"data"`
-	Message string    `json:"message"`
-	Success bool      `json:"success"`
+func (r *UpdateWebhookRequest) validate() error {
+	if r.URL != nil && *r.URL == "" {
+		return &ValidationError{
+			Message: "url must not be empty",
+			Fields:  []FieldError{{Field: "url", Message: "must not be empty"}},
+		}
+	}
+	if r.AuthMethod == nil {
+		if r.UserName != nil || r.Password != nil || r.APIKey != nil || r.APIKeyHeader != nil ||
+			r.HMACHeader != nil || r.HMACSecret != nil {
+			return &ValidationError{
+				Message: "authMethod is required when changing webhook credentials",
+				Fields:  []FieldError{{Field: "authMethod", Message: "is required"}},
+			}
+		}
+		return nil
+	}
+	return validateWebhookAuth(&CreateWebhookRequest{
+		AuthMethod:   *r.AuthMethod,
+		UserName:     deref(r.UserName),
+		Password:     deref(r.Password),
+		APIKey:       deref(r.APIKey),
+		APIKeyHeader: deref(r.APIKeyHeader),
+		HMACHeader:   deref(r.HMACHeader),
+		HMACSecret:   deref(r.HMACSecret),
+	})
 }
 
 func validateWebhookAuth(req *CreateWebhookRequest) error {
+	if req.Auth != nil {
+		if req.AuthMethod != "" || len(setFields(req.credentials())) > 0 {
+			return &ValidationError{
+				Message: "set either Auth or AuthMethod with its credential fields, not both",
+				Fields:  []FieldError{{Field: "authMethod", Message: "conflicts with Auth"}},
+			}
+		}
+		req = req.wire()
+	}
+
 	if req.AuthMethod == "" {
-		return nil
+		return rejectFields("credential fields require an authMethod", setFields(req.credentials()))
 	}
 
+	var err error
 	switch AuthMethod(req.AuthMethod) {
 	case AuthMethodBasic:
-		if req.UserName == "" || req.Password == "" {
-			return errors.New("for basic auth, userName and password are required")
-		}
+		err = requireFields("for basic auth, userName and password are required",
+			field{"userName", req.UserName}, field{"password", req.Password})
 	case AuthMethodHMAC:
-		if req.HMACHeader == "" || req.HMACSecret == "" {
-			return errors.New("for hmac auth, hmacHeader and hmacSecret are required")
-		}
+		err = requireFields("for hmac auth, hmacHeader and hmacSecret are required",
+			field{"hmacHeader", req.HMACHeader}, field{"hmacSecret", req.HMACSecret})
 	case AuthMethodAPIKey:
-		if req.APIKey == "" || req.APIKeyHeader == "" {
-			return errors.New("for apiKey auth, apiKey and apiKeyHeader are required")
-		}
+		err = requireFields("for apiKey auth, apiKey and apiKeyHeader are required",
+			field{"apiKey", req.APIKey}, field{"apiKeyHeader", req.APIKeyHeader})
 	default:
-		return fmt.Errorf("invalid auth method: %s", req.AuthMethod)
+		return &ValidationError{
+			Message: fmt.Sprintf("invalid auth method: %s", req.AuthMethod),
+			Fields:  []FieldError{{Field: "authMethod", Message: "must be one of basic, apiKey, hmac"}},
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	var foreign []field
+	for _, f := range setFields(req.credentials()) {
+		if authMethodOf[f.name] != AuthMethod(req.AuthMethod) {
+			foreign = append(foreign, f)
+		}
 	}
+	return rejectFields(fmt.Sprintf("for %s auth, only its own credential fields may be set", req.AuthMethod), foreign)
+}
+
+// authMethodOf maps each flattened credential field to the auth method it
+// belongs to.
+var authMethodOf = map[string]AuthMethod{
+	"userName":     AuthMethodBasic,
+	"password":     AuthMethodBasic,
+	"apiKey":       AuthMethodAPIKey,
+	"apiKeyHeader": AuthMethodAPIKey,
+	"hmacHeader":   AuthMethodHMAC,
+	"hmacSecret":   AuthMethodHMAC,
+}
+
+// credentials returns the flattened credential fields of r.
+func (r *CreateWebhookRequest) credentials() []field {
+	return []field{
+		{"userName", r.UserName}, {"password", r.Password},
+		{"apiKey", r.APIKey}, {"apiKeyHeader", r.APIKeyHeader},
+		{"hmacHeader", r.HMACHeader}, {"hmacSecret", r.HMACSecret},
+	}
+}
 
-	return nil
+// setFields returns the fields with a non-empty value.
+func setFields(fields []field) []field {
+	var set []field
+	for _, f := range fields {
+		if f.value != "" {
+			set = append(set, f)
+		}
+	}
+	return set
 }
 
-func (s *WebhookService) Create(ctx context.Context, req *CreateWebhookRequest) (*WebhookResponse, error) {
+// rejectFields returns a *ValidationError with message listing fields as
+// not allowed, or nil when fields is empty.
+func rejectFields(message string, fields []field) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	errs := make([]FieldError, 0, len(fields))
+	for _, f := range fields {
+		errs = append(errs, FieldError{Field: f.name, Message: "is not allowed"})
+	}
+	return &ValidationError{Message: message, Fields: errs}
+}
+
+// field pairs a request field name with its value for requireFields.
+type field struct {
+	name  string
+	value string
+}
+
+// requireFields returns a *ValidationError with message, listing every empty
+// field, or nil when all are set.
+func requireFields(message string, fields ...field) error {
+	var missing []FieldError
+	for _, f := range fields {
+		if f.value == "" {
+			missing = append(missing, FieldError{Field: f.name, Message: "is required"})
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return &ValidationError{Message: message, Fields: missing}
+}
+
+func (s *WebhookService) Create(ctx context.Context, req *CreateWebhookRequest, opts ...RequestOption) (*Response[Webhook], error) {
 	if err := validateWebhookAuth(req); err != nil {
 		return nil, err
 	}
 
-	// Send the request exactly as provided, since it matches the server's validation schema
-	resp := &WebhookResponse{}
-	httpResp, err := s.client.resty.R().
-		SetContext(ctx).
-		SetBody(req). // Send the request directly without restructuring
+	// The request marshals to the server's flattened schema, expanding Auth if set
+	resp := &Response[Webhook]{}
+	httpResp, err := s.client.newRequest(ctx, opts...).
+		SetBody(req).
 		SetResult(resp).
 		Post("/webhooks")
 	if err != nil {
 		return nil, fmt.Errorf("failed to create webhook: %w", err)
 	}
 
-	// Debug logging
-	fmt.Printf("Webhook Create Response Status: %s\n", httpResp.Status())
-	fmt.Printf("Webhook Create Response Body: %s\n", string(httpResp.Body()))
+	if err := checkResponse(httpResp); err != nil {
+		return nil, err
+	}
 
 	if !resp.Success {
-		return nil, fmt.Errorf("webhook creation failed: %s", resp.Message)
+		return nil, unsuccessful(httpResp, "webhook creation failed: "+resp.Message)
 	}
 
+	resp.setMeta(httpResp)
 	return resp, nil
 }
 
-func (s *WebhookService) GetAll(ctx context.Context, appID string) (*WebhookListResponse, error) {
-	resp := &WebhookListResponse{}
-	httpResp, err := s.client.resty.R().
-		SetContext(ctx).
+func (s *WebhookService) GetAll(ctx context.Context, appID string) (*Response[[]Webhook], error) {
+	resp := &Response[[]Webhook]{}
+	httpResp, err := s.client.newRequest(ctx).
 		SetQueryParam("appId", appID).
 		SetResult(resp).
 		Get("/webhooks")
@@ -137,76 +274,82 @@ func (s *WebhookService) GetAll(ctx context.Context, appID string) (*WebhookList
 		return nil, fmt.Errorf("failed to get webhooks: %w", err)
 	}
 
-	// Debug logging
-	fmt.Printf("Webhook List Response Status: %s\n", httpResp.Status())
-	fmt.Printf("Webhook List Response Body: %s\n", string(httpResp.Body()))
+	if err := checkResponse(httpResp); err != nil {
+		return nil, err
+	}
 
 	if !resp.Success {
-		return nil, fmt.Errorf("webhook list retrieval failed: %s", resp.Message)
-	}
-
-	// Ensure each webhook has its auth method properly set
-	for i := range resp.Data {
-		if resp.Data[i].AuthMethod == nil {
-			// If authMethod is nil but we have auth data in the response,
-			// try to reconstruct it from the raw response
-			var rawData map[string]interface{}
-			if err := json.Unmarshal(httpResp.Body(), &rawData); err == nil {
-				if data, ok := rawData["data"].([]interface{}); ok && i < len(data) {
-					if webhook, ok := data[i].(map[string]interface{}); ok {
-						if authMethod, ok := webhook["authMethod"].(map[string]interface{}); ok {
-							resp.Data[i].AuthMethod = &WebhookAuth{
-								Method:     AuthMethod(authMethod["method"].(string)),
-								HMACHeader: authMethod["hmacHeader"].(string),
-								HMACSecret: authMethod["hmacSecret"].(string),
-							}
-						}
-					}
-				}
-			}
-		}
+		return nil, unsuccessful(httpResp, "webhook list retrieval failed: "+resp.Message)
 	}
 
+	resp.setMeta(httpResp)
 	return resp, nil
 }
 
-func (s *WebhookService) GetOne(ctx context.Context, webhookID string) (*WebhookResponse, error) {
-	resp := &WebhookResponse{}
-	httpResp, err := s.client.resty.R().
-		SetContext(ctx).
+func (s *WebhookService) GetOne(ctx context.Context, webhookID string) (*Response[Webhook], error) {
+	resp := &Response[Webhook]{}
+	httpResp, err := s.client.newRequest(ctx).
 		SetResult(resp).
 		Get("/webhooks/" + webhookID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get webhook: %w", err)
 	}
 
-	// Debug logging
-	fmt.Printf("Webhook Get Response Status: %s\n", httpResp.Status())
-	fmt.Printf("Webhook Get Response Body: %s\n", string(httpResp.Body()))
+	if err := checkResponse(httpResp); err != nil {
+		return nil, err
+	}
 
 	if !resp.Success {
-		return nil, fmt.Errorf("webhook retrieval failed: %s", resp.Message)
+		return nil, unsuccessful(httpResp, "webhook retrieval failed: "+resp.Message)
 	}
 
+	resp.setMeta(httpResp)
 	return resp, nil
 }
 
-func (s *WebhookService) Update(ctx context.Context, webhookID string, req map[string]interface{}) (*WebhookResponse, error) {
-	resp := &WebhookResponse{}
-	_, err := s.client.resty.R().
-		SetContext(ctx).
+// Update sends req to the webhook update endpoint without validation.
+//
+// Deprecated: Use UpdateWebhook with an UpdateWebhookRequest, which catches
+// misspelled fields and incomplete auth settings before they are sent.
+func (s *WebhookService) Update(ctx context.Context, webhookID string, req map[string]interface{}) (*Response[Webhook], error) {
+	return s.update(ctx, webhookID, req)
+}
+
+// UpdateWebhook changes the fields of a webhook that are set in req. The
+// request is validated with the same rules as Create before it is sent.
+// Example:
+//
+//	updated, err := client.Webhook.UpdateWebhook(ctx, "WEBHOOK_ID", &vartiq.UpdateWebhookRequest{
+//	    URL: vartiq.Ptr("https://example.com/new-endpoint"),
+//	})
+func (s *WebhookService) UpdateWebhook(ctx context.Context, webhookID string, req *UpdateWebhookRequest) (*Response[Webhook], error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+	return s.update(ctx, webhookID, req)
+}
+
+func (s *WebhookService) update(ctx context.Context, webhookID string, req interface{}) (*Response[Webhook], error) {
+	resp := &Response[Webhook]{}
+	httpResp, err := s.client.newRequest(ctx).
 		SetBody(req).
 		SetResult(resp).
 		Put("/webhooks/" + webhookID)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkResponse(httpResp); err != nil {
+		return nil, err
+	}
+	resp.setMeta(httpResp)
 	return resp, nil
 }
 
 func (s *WebhookService) Delete(ctx context.Context, webhookID string) error {
-	_, err := s.client.resty.R().
-		SetContext(ctx).
+	httpResp, err := s.client.newRequest(ctx).
 		Delete("/webhooks/" + webhookID)
-	return err
+	if err != nil {
+		return err
+	}
+	return checkResponse(httpResp)
 }
@@ -0,0 +1,174 @@
+package vartiq
+
+import "encoding/json"
+
+// WebhookAuthConfig is the authentication Vartiq uses when calling a webhook.
+// It is implemented only by BasicAuthConfig, APIKeyAuthConfig and
+// HMACAuthConfig; build one with BasicAuth, APIKeyAuth or HMACAuth.
+type WebhookAuthConfig interface {
+	// Method returns the auth method the configuration is sent as.
+	Method() AuthMethod
+
+	// applyTo fills the flattened wire fields of req. Being unexported,
+	// it keeps the set of implementations closed to this package.
+	applyTo(req *CreateWebhookRequest)
+}
+
+// BasicAuthConfig authenticates webhook calls with HTTP basic auth.
+type BasicAuthConfig struct {
+	UserName string
+	Password string
+}
+
+// APIKeyAuthConfig authenticates webhook calls by sending Key in Header.
+type APIKeyAuthConfig struct {
+	Header string
+	Key    string
+}
+
+// HMACAuthConfig signs webhook calls with HMAC-SHA256 of the body using
+// Secret, sent in Header.
+type HMACAuthConfig struct {
+	Header string
+	Secret string
+}
+
+// BasicAuth returns a basic auth configuration.
+func BasicAuth(userName, password string) BasicAuthConfig {
+	return BasicAuthConfig{UserName: userName, Password: password}
+}
+
+// APIKeyAuth returns an API key configuration sending key in header.
+func APIKeyAuth(header, key string) APIKeyAuthConfig {
+	return APIKeyAuthConfig{Header: header, Key: key}
+}
+
+// HMACAuth returns an HMAC signature configuration sending the signature in header.
+func HMACAuth(header, secret string) HMACAuthConfig {
+	return HMACAuthConfig{Header: header, Secret: secret}
+}
+
+func (BasicAuthConfig) Method() AuthMethod  { return AuthMethodBasic }
+func (APIKeyAuthConfig) Method() AuthMethod { return AuthMethodAPIKey }
+func (HMACAuthConfig) Method() AuthMethod   { return AuthMethodHMAC }
+
+func (c BasicAuthConfig) applyTo(req *CreateWebhookRequest) {
+	req.AuthMethod = string(AuthMethodBasic)
+	req.UserName, req.Password = c.UserName, c.Password
+}
+
+func (c APIKeyAuthConfig) applyTo(req *CreateWebhookRequest) {
+	req.AuthMethod = string(AuthMethodAPIKey)
+	req.APIKeyHeader, req.APIKey = c.Header, c.Key
+}
+
+func (c HMACAuthConfig) applyTo(req *CreateWebhookRequest) {
+	req.AuthMethod = string(AuthMethodHMAC)
+	req.HMACHeader, req.HMACSecret = c.Header, c.Secret
+}
+
+// Config converts the auth settings returned by the API into the matching
+// WebhookAuthConfig. It returns nil for a nil receiver or an unknown method.
+func (a *WebhookAuth) Config() WebhookAuthConfig {
+	if a == nil {
+		return nil
+	}
+	switch a.Method {
+	case AuthMethodBasic:
+		return BasicAuth(a.UserName, a.Password)
+	case AuthMethodAPIKey:
+		return APIKeyAuth(a.APIKeyHeader, a.APIKey)
+	case AuthMethodHMAC:
+		return HMACAuth(a.HMACHeader, a.HMACSecret)
+	}
+	return nil
+}
+
+// Auth returns the webhook's authentication as a WebhookAuthConfig, or nil
+// when the webhook has none.
+func (w *Webhook) Auth() WebhookAuthConfig {
+	return w.AuthMethod.Config()
+}
+
+// wire returns req in the flattened wire format, with Auth expanded into
+// AuthMethod and its credential fields.
+func (r *CreateWebhookRequest) wire() *CreateWebhookRequest {
+	if r.Auth == nil {
+		return r
+	}
+	flat := *r
+	flat.Auth = nil
+	r.Auth.applyTo(&flat)
+	return &flat
+}
+
+// MarshalJSON encodes the request in the API's flattened format, expanding
+// Auth when it is set.
+func (r CreateWebhookRequest) MarshalJSON() ([]byte, error) {
+	type plain CreateWebhookRequest
+	return json.Marshal(plain(*r.wire()))
+}
+
+// UnmarshalJSON accepts the auth method either as an object
+// ({"method": "hmac", "hmacHeader": ...}) or as a bare method name.
+func (a *WebhookAuth) UnmarshalJSON(data []byte) error {
+	var method string
+	if err := json.Unmarshal(data, &method); err == nil {
+		*a = WebhookAuth{Method: AuthMethod(method)}
+		return nil
+	}
+	type plain WebhookAuth
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	*a = WebhookAuth(p)
+	return nil
+}
+
+// UnmarshalJSON decodes a webhook, normalizing its auth method. The API may
+// return authMethod as an object, as a bare method name with the credential
+// fields alongside it on the webhook, or not at all; AuthMethod is nil when
+// the webhook has no authentication.
+func (w *Webhook) UnmarshalJSON(data []byte) error {
+	type plain Webhook
+	var wire struct {
+		plain
+		AuthMethod json.RawMessage `json:"authMethod"`
+		// Credential fields sent next to a bare method name.
+		UserName     string `json:"userName"`
+		Password     string `json:"password"`
+		APIKey       string `json:"apiKey"`
+		APIKeyHeader string `json:"apiKeyHeader"`
+		HMACHeader   string `json:"hmacHeader"`
+		HMACSecret   string `json:"hmacSecret"`
+	}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	*w = Webhook(wire.plain)
+
+	if len(wire.AuthMethod) == 0 || string(wire.AuthMethod) == "null" {
+		return nil
+	}
+	auth := &WebhookAuth{}
+	if err := json.Unmarshal(wire.AuthMethod, auth); err != nil {
+		return err
+	}
+	if auth.Method == "" {
+		return nil
+	}
+	fill := func(dst *string, src string) {
+		if *dst == "" {
+			*dst = src
+		}
+	}
+	fill(&auth.UserName, wire.UserName)
+	fill(&auth.Password, wire.Password)
+	fill(&auth.APIKey, wire.APIKey)
+	fill(&auth.APIKeyHeader, wire.APIKeyHeader)
+	fill(&auth.HMACHeader, wire.HMACHeader)
+	fill(&auth.HMACSecret, wire.HMACSecret)
+	w.AuthMethod = auth
+	return nil
+}
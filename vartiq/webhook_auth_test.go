@@ -0,0 +1,202 @@
+package vartiq
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookAuthConfig_Marshal(t *testing.T) {
+	tests := []struct {
+		name     string
+		auth     WebhookAuthConfig
+		expected string
+	}{
+		{
+			name:     "Basic",
+			auth:     BasicAuth("user", "pass"),
+			expected: `{"url":"http://example.com","appId":"app","authMethod":"basic","userName":"user","password":"pass"}`,
+		},
+		{
+			name:     "API key",
+			auth:     APIKeyAuth("X-API-Key", "key123"),
+			expected: `{"url":"http://example.com","appId":"app","authMethod":"apiKey","apiKey":"key123","apiKeyHeader":"X-API-Key"}`,
+		},
+		{
+			name:     "HMAC",
+			auth:     HMACAuth("x-Vartiq-signature", "secret123"),
+			expected: `{"url":"http://example.com","appId":"app","authMethod":"hmac","hmacHeader":"x-Vartiq-signature","hmacSecret":"secret123"}`,
+		},
+		{
+			name:     "None",
+			expected: `{"url":"http://example.com","appId":"app"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &CreateWebhookRequest{URL: "http://example.com", AppID: "app", Auth: tt.auth}
+			require.NoError(t, validateWebhookAuth(req))
+			body, err := json.Marshal(req)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(body))
+		})
+	}
+}
+
+func TestWebhookAuthConfig_Validation(t *testing.T) {
+	tests := []struct {
+		name          string
+		request       *CreateWebhookRequest
+		expectedError string
+	}{
+		{
+			name:          "Auth with flattened fields",
+			request:       &CreateWebhookRequest{Auth: HMACAuth("x-sig", "secret"), Password: "pass"},
+			expectedError: "set either Auth or AuthMethod with its credential fields, not both",
+		},
+		{
+			name:          "Incomplete typed auth",
+			request:       &CreateWebhookRequest{Auth: BasicAuth("user", "")},
+			expectedError: "for basic auth, userName and password are required",
+		},
+		{
+			name: "Flattened fields of another method",
+			request: &CreateWebhookRequest{
+				AuthMethod: string(AuthMethodHMAC),
+				HMACHeader: "x-sig",
+				HMACSecret: "secret",
+				Password:   "pass",
+			},
+			expectedError: "for hmac auth, only its own credential fields may be set",
+		},
+		{
+			name:          "Credentials without auth method",
+			request:       &CreateWebhookRequest{APIKey: "key"},
+			expectedError: "credential fields require an authMethod",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWebhookAuth(tt.request)
+			assert.EqualError(t, err, tt.expectedError)
+			assert.ErrorIs(t, err, ErrValidation)
+		})
+	}
+}
+
+func TestWebhookAuth_Config(t *testing.T) {
+	tests := []struct {
+		auth     *WebhookAuth
+		expected WebhookAuthConfig
+	}{
+		{&WebhookAuth{Method: AuthMethodBasic, UserName: "user", Password: "pass"}, BasicAuth("user", "pass")},
+		{&WebhookAuth{Method: AuthMethodAPIKey, APIKeyHeader: "X-Key", APIKey: "key"}, APIKeyAuth("X-Key", "key")},
+		{&WebhookAuth{Method: AuthMethodHMAC, HMACHeader: "x-sig", HMACSecret: "secret"}, HMACAuth("x-sig", "secret")},
+		{&WebhookAuth{Method: "oauth"}, nil},
+		{nil, nil},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, tt.auth.Config())
+	}
+}
+
+func TestWebhookService_CreateWithTypedAuth(t *testing.T) {
+	var sent map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &sent)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"id":"wh-1","authMethod":{"method":"hmac","hmacHeader":"x-sig","hmacSecret":"secret"}},"success":true}`))
+	}))
+	defer srv.Close()
+
+	client := New("test-key", srv.URL)
+	resp, err := client.Webhook.Create(context.Background(), &CreateWebhookRequest{
+		URL:   "http://example.com",
+		AppID: "app",
+		Auth:  HMACAuth("x-sig", "secret"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "hmac", sent["authMethod"])
+	assert.Equal(t, "secret", sent["hmacSecret"])
+
+	switch auth := resp.Data.Auth().(type) {
+	case HMACAuthConfig:
+		assert.Equal(t, "x-sig", auth.Header)
+	default:
+		t.Fatalf("unexpected auth config %T", auth)
+	}
+}
+
+func TestWebhook_UnmarshalAuthMethod(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected *WebhookAuth
+	}{
+		{
+			name:     "HMAC object",
+			body:     `{"id":"wh","authMethod":{"method":"hmac","hmacHeader":"x-sig","hmacSecret":"secret"}}`,
+			expected: &WebhookAuth{Method: AuthMethodHMAC, HMACHeader: "x-sig", HMACSecret: "secret"},
+		},
+		{
+			name:     "Basic object",
+			body:     `{"id":"wh","authMethod":{"method":"basic","userName":"user","password":"pass"}}`,
+			expected: &WebhookAuth{Method: AuthMethodBasic, UserName: "user", Password: "pass"},
+		},
+		{
+			name:     "API key object with missing fields",
+			body:     `{"id":"wh","authMethod":{"method":"apiKey","apiKeyHeader":"X-Key"}}`,
+			expected: &WebhookAuth{Method: AuthMethodAPIKey, APIKeyHeader: "X-Key"},
+		},
+		{
+			name:     "Method name with flattened credentials",
+			body:     `{"id":"wh","authMethod":"apiKey","apiKey":"key","apiKeyHeader":"X-Key"}`,
+			expected: &WebhookAuth{Method: AuthMethodAPIKey, APIKey: "key", APIKeyHeader: "X-Key"},
+		},
+		{
+			name:     "Method name without credentials",
+			body:     `{"id":"wh","authMethod":"basic"}`,
+			expected: &WebhookAuth{Method: AuthMethodBasic},
+		},
+		{name: "Empty method name", body: `{"id":"wh","authMethod":""}`},
+		{name: "Null", body: `{"id":"wh","authMethod":null}`},
+		{name: "Missing", body: `{"id":"wh"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var webhook Webhook
+			require.NoError(t, json.Unmarshal([]byte(tt.body), &webhook))
+			assert.Equal(t, "wh", webhook.ID)
+			assert.Equal(t, tt.expected, webhook.AuthMethod)
+		})
+	}
+
+	var webhook Webhook
+	assert.Error(t, json.Unmarshal([]byte(`{"id":"wh","authMethod":42}`), &webhook))
+}
+
+func TestWebhookService_GetAllMixedAuth(t *testing.T) {
+	client := newTestServer(t, http.StatusOK, `{"data":[
+		{"id":"wh-1","authMethod":{"method":"basic","userName":"user","password":"pass"}},
+		{"id":"wh-2","authMethod":"hmac","hmacHeader":"x-sig","hmacSecret":"secret"},
+		{"id":"wh-3"}
+	],"success":true}`, nil)
+
+	resp, err := client.Webhook.GetAll(context.Background(), "app")
+	require.NoError(t, err)
+	require.Len(t, resp.Data, 3)
+	assert.Equal(t, BasicAuth("user", "pass"), resp.Data[0].Auth())
+	assert.Equal(t, HMACAuth("x-sig", "secret"), resp.Data[1].Auth())
+	assert.Nil(t, resp.Data[2].Auth())
+}
@@ -3,119 +3,810 @@ package vartiq
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
-// Error represents an API error response
-type Error struct {
-	Message string
-}
+// signatureHeaderKey is the message header holding the payload signature.
+const signatureHeaderKey = "x-Vartiq-signature"
 
-func (e *Error) Error() string {
-	return e.Message
-}
+// Error represents an API error response.
+//
+// Deprecated: Use APIError, which Error is an alias of.
+type Error = APIError
 
 type WebhookMessageService struct {
 	client *Client
 }
 
 type WebhookMessage struct {
-	ID          string      `json:"id"`
-	AppID       string      `json:"app"`
-	Payload     interface{} `json:"payload"`
-	Signature   string      `json:"signature"`
-	IsDelivered bool        `json:"isDelivered"`
-	CreatedAt   string      `json:"createdAt"`
-	UpdatedAt   string      `json:"updatedAt"`
+	ID        string      `json:"id"`
+	AppID     string      `json:"app"`
+	WebhookID string      `json:"webhook,omitempty"`
+	EventType string      `json:"eventType,omitempty"`
+	Payload   interface{} `json:"payload"`
+	// RawPayload holds the payload's JSON as sent by the API, for use with
+	// DecodePayload or without decoding.
+	RawPayload  json.RawMessage `json:"-"`
+	Signature   string          `json:"signature"`
+	IsDelivered bool            `json:"isDelivered"`
+	CreatedAt   string          `json:"createdAt"`
+	UpdatedAt   string          `json:"updatedAt"`
+}
+
+// webhookMessageWire is a webhook message as the API encodes it.
+type webhookMessageWire struct {
+	ID        string `json:"id"`
+	AppID     string `json:"app"`
+	WebhookID string `json:"webhook"`
+	EventType string `json:"eventType"`
+	// API returns payload as JSON string; a plain JSON value is accepted too.
+	Payload     json.RawMessage `json:"payload"`
+	Headers     []Header        `json:"headers"`
+	IsDelivered bool            `json:"isDelivered"`
+	CreatedAt   string          `json:"createdAt"`
+	UpdatedAt   string          `json:"updatedAt"`
+}
+
+// decode converts the wire form into a WebhookMessage, parsing the payload.
+func (m *webhookMessageWire) decode() (WebhookMessage, error) {
+	raw := []byte(m.Payload)
+	var encoded string
+	if err := json.Unmarshal(raw, &encoded); err == nil {
+		raw = []byte(encoded)
+	}
+
+	var payload interface{}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return WebhookMessage{}, fmt.Errorf("failed to parse payload: %w", err)
+		}
+	}
+
+	var signature string
+	for _, header := range m.Headers {
+		if strings.EqualFold(header.Key, signatureHeaderKey) {
+			signature = header.Value
+			break
+		}
+	}
+
+	return WebhookMessage{
+		ID:          m.ID,
+		AppID:       m.AppID,
+		WebhookID:   m.WebhookID,
+		EventType:   m.EventType,
+		Payload:     payload,
+		RawPayload:  raw,
+		Signature:   signature,
+		IsDelivered: m.IsDelivered,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}, nil
 }
 
 type webhookMessageResponse struct {
 	Data struct {
-		WebhookMessages []struct {
-			ID      string `json:"id"`
-			AppID   string `json:"app"`
-			Payload string `json:"payload"` // API returns payload as JSON string
-			Headers []struct {
-				Key   string `json:"key"`
-				Value string `json:"value"`
-			} `json:"headers"`
-			IsDelivered bool   `json:"isDelivered"`
-			CreatedAt   string `json:"createdAt"`
-			UpdatedAt   string `json:"updatedAt"`
-		} `json:"webhookMessages"`
+		WebhookMessages []webhookMessageWire `json:"webhookMessages"`
 	} `json:"data"`
 	Message string `json:"message"`
 	Success bool   `json:"success"`
 }
 
-type WebhookMessageResponse struct {
-	Data    WebhookMessage `json:"data"`
-	Message string         `json:"message"`
-	Success bool           `json:"success"`
-}
+// WebhookMessageResponse is kept for compatibility; it is the same type as Response[WebhookMessage].
+type WebhookMessageResponse = Response[WebhookMessage]
 
 // Create sends a message to a webhook. The payload can be any JSON-serializable value.
+// Every call carries an idempotency key, generated unless WithIdempotencyKey
+// is given, so that retries never deliver the same message twice. Use
+// WithEventType to deliver it only to the webhooks subscribed to that type;
+// with WithSchemaValidation the payload is first checked against the event
+// type's schema.
 // Example:
 //
 //	message, err := client.WebhookMessage.Create(ctx, "APP_ID", map[string]interface{}{
 //	    "hello": "world",
-//	})
-func (s *WebhookMessageService) Create(ctx context.Context, appID string, payload interface{}) (*WebhookMessageResponse, error) {
+//	}, vartiq.WithIdempotencyKey(event.ID), vartiq.WithEventType("invoice.paid"))
+func (s *WebhookMessageService) Create(ctx context.Context, appID string, payload interface{}, opts ...RequestOption) (*Response[WebhookMessage], error) {
+	opts = append([]RequestOption{WithIdempotencyKey(NewIdempotencyKey())}, opts...)
+
+	body := map[string]interface{}{
+		"appId":   appID,
+		"payload": payload,
+	}
+	if o := applyOptions(opts); o.eventType != "" {
+		body["eventType"] = o.eventType
+		payloadSchema, err := s.client.payloadSchema(ctx, appID, o.eventType)
+		if err != nil {
+			return nil, err
+		}
+		if payloadSchema != nil {
+			if err := validatePayload(payloadSchema, o.eventType, payload); err != nil {
+				return nil, err
+			}
+		}
+	}
 	resp := &webhookMessageResponse{}
-	httpResp, err := s.client.resty.R().
-		SetContext(ctx).
-		SetBody(map[string]interface{}{
-			"appId":   appID,
-			"payload": payload,
-		}).
+	httpResp, err := s.client.newRequest(ctx, opts...).
+		SetBody(body).
 		SetResult(resp).
 		Post("/webhook-messages")
 	if err != nil {
 		return nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
 
-	// Debug logging
-	fmt.Printf("Response Status: %s\n", httpResp.Status())
-	fmt.Printf("Response Body: %s\n", string(httpResp.Body()))
+	if err := checkResponse(httpResp); err != nil {
+		return nil, err
+	}
 
 	if !resp.Success {
-		return nil, &Error{Message: resp.Message}
+		return nil, unsuccessful(httpResp, resp.Message)
 	}
 
 	if len(resp.Data.WebhookMessages) == 0 {
-		return nil, &Error{Message: "No webhook messages returned"}
+		return nil, unsuccessful(httpResp, "No webhook messages returned")
 	}
 
-	rawMessage := resp.Data.WebhookMessages[0]
+	message, err := resp.Data.WebhookMessages[0].decode()
+	if err != nil {
+		return nil, err
+	}
+	message.AppID = appID // Use the provided appID
 
-	// Parse the payload JSON string back into an interface{}
-	var parsedPayload interface{}
-	if err := json.Unmarshal([]byte(rawMessage.Payload), &parsedPayload); err != nil {
-		return nil, fmt.Errorf("failed to parse payload: %w", err)
+	result := &Response[WebhookMessage]{
+		Data:    message,
+		Message: resp.Message,
+		Success: resp.Success,
 	}
+	result.setMeta(httpResp)
+	return result, nil
+}
 
-	// Extract signature from headers
-	var signature string
-	for _, header := range rawMessage.Headers {
-		if header.Key == "x-Vartiq-signature" {
-			signature = header.Value
-			break
+// MaxBatchSize is the largest number of messages the API accepts in one
+// batch request. CreateBatch splits larger batches into chunks of this size.
+const MaxBatchSize = 100
+
+// BatchResult is the outcome of one payload passed to CreateBatch.
+type BatchResult struct {
+	// Index is the payload's position in the slice passed to CreateBatch.
+	Index int
+	// Message is the created message; it is set only when Err is nil.
+	Message WebhookMessage
+	Err     error
+	// RequestFailed reports that Err is the failure of the whole request
+	// carrying the payload, a transport error or a non-2xx response, rather
+	// than an outcome the API returned for this payload. Only then does
+	// resending under the same idempotency key send it again; otherwise the
+	// API replays its stored response.
+	RequestFailed bool
+}
+
+// batchItemWire is one entry of a batch response: the messages created for
+// a payload, or the error that prevented it.
+type batchItemWire struct {
+	WebhookMessages []webhookMessageWire `json:"webhookMessages"`
+	Error           *APIError            `json:"error"`
+}
+
+// CreateBatch sends many messages to an app with one request per
+// MaxBatchSize payloads. It returns one result per payload, in order; a
+// payload the API rejects, or a chunk whose request fails, sets Err on the
+// affected results without failing the others. The error return is reserved
+// for ctx ending, in which case the results for unsent payloads carry ctx's
+// error too. Every chunk carries an idempotency key derived from the one
+// given with WithIdempotencyKey, or a generated one. WithEventType applies
+// to every payload; with WithSchemaValidation, payloads not matching the
+// event type's schema get a *ValidationError and are not sent.
+// Example:
+//
+//	results, err := client.WebhookMessage.CreateBatch(ctx, "APP_ID", []interface{}{event1, event2})
+//	for _, r := range results {
+//	    if r.Err != nil {
+//	        log.Printf("payload %d: %v", r.Index, r.Err)
+//	    }
+//	}
+func (s *WebhookMessageService) CreateBatch(ctx context.Context, appID string, payloads []interface{}, opts ...RequestOption) ([]BatchResult, error) {
+	o := applyOptions(append([]RequestOption{WithIdempotencyKey(NewIdempotencyKey())}, opts...))
+
+	results := make([]BatchResult, len(payloads))
+	payloadSchema, err := s.client.payloadSchema(ctx, appID, o.eventType)
+	if err != nil {
+		for i := range results {
+			results[i] = BatchResult{Index: i, Err: err, RequestFailed: true}
+		}
+		return results, ctx.Err()
+	}
+	// send holds the indexes of the payloads to send.
+	send := make([]int, 0, len(payloads))
+	for i, payload := range payloads {
+		if payloadSchema != nil {
+			if err := validatePayload(payloadSchema, o.eventType, payload); err != nil {
+				results[i] = BatchResult{Index: i, Err: err}
+				continue
+			}
 		}
+		send = append(send, i)
 	}
 
-	message := WebhookMessage{
-		ID:          rawMessage.ID,
-		AppID:       appID, // Use the provided appID
-		Payload:     parsedPayload,
-		Signature:   signature,
-		IsDelivered: rawMessage.IsDelivered,
-		CreatedAt:   rawMessage.CreatedAt,
-		UpdatedAt:   rawMessage.UpdatedAt,
+	for start := 0; start < len(send); start += MaxBatchSize {
+		end := start + MaxBatchSize
+		if end > len(send) {
+			end = len(send)
+		}
+		chunk := make([]interface{}, end-start)
+		for i, index := range send[start:end] {
+			chunk[i] = payloads[index]
+		}
+		key := fmt.Sprintf("%s-%d", o.idempotencyKey, start/MaxBatchSize)
+		chunkResults := s.createChunk(ctx, appID, o.eventType, chunk, key)
+		for i, index := range send[start:end] {
+			chunkResults[i].Index = index
+			results[index] = chunkResults[i]
+		}
 	}
+	return results, ctx.Err()
+}
 
-	return &WebhookMessageResponse{
+// createChunk sends one batch request and returns a result per payload,
+// with Index left for the caller to set.
+func (s *WebhookMessageService) createChunk(ctx context.Context, appID, eventType string, payloads []interface{}, key string) []BatchResult {
+	results := make([]BatchResult, len(payloads))
+	fail := func(err error, requestFailed bool) []BatchResult {
+		for i := range results {
+			results[i] = BatchResult{Err: err, RequestFailed: requestFailed}
+		}
+		return results
+	}
+
+	messages := make([]map[string]interface{}, len(payloads))
+	for i, payload := range payloads {
+		messages[i] = map[string]interface{}{"payload": payload}
+		if eventType != "" {
+			messages[i]["eventType"] = eventType
+		}
+	}
+
+	resp := &Response[struct {
+		Results []batchItemWire `json:"results"`
+	}]{}
+	httpResp, err := s.client.newRequest(ctx, WithIdempotencyKey(key)).
+		SetBody(map[string]interface{}{
+			"appId":    appID,
+			"messages": messages,
+		}).
+		SetResult(resp).
+		Post("/webhook-messages/batch")
+	if err != nil {
+		return fail(fmt.Errorf("HTTP request failed: %w", err), true)
+	}
+	if err := checkResponse(httpResp); err != nil {
+		return fail(err, true)
+	}
+	if !resp.Success {
+		return fail(unsuccessful(httpResp, resp.Message), false)
+	}
+	if len(resp.Data.Results) != len(payloads) {
+		return fail(unsuccessful(httpResp, fmt.Sprintf("batch returned %d results for %d messages", len(resp.Data.Results), len(payloads))), false)
+	}
+
+	for i, item := range resp.Data.Results {
+		var result BatchResult
+		switch {
+		case item.Error != nil:
+			item.Error.RequestID = httpResp.Header().Get(requestIDHeader)
+			result.Err = item.Error
+		case len(item.WebhookMessages) == 0:
+			result.Err = unsuccessful(httpResp, "No webhook messages returned")
+		default:
+			if result.Message, result.Err = item.WebhookMessages[0].decode(); result.Err == nil {
+				result.Message.AppID = appID
+			}
+		}
+		results[i] = result
+	}
+	return results
+}
+
+// WebhookMessageFilter narrows the messages returned by List. Zero fields
+// do not filter.
+type WebhookMessageFilter struct {
+	// Delivered selects delivered (true) or undelivered (false) messages.
+	Delivered *bool
+	// Since and Until bound the creation time, inclusive and exclusive.
+	Since time.Time
+	Until time.Time
+	// EventType selects messages sent with WithEventType for this event
+	// type.
+	EventType string
+	// Limit caps the number of messages returned; Offset skips that many
+	// matching messages first, for paging.
+	Limit  int
+	Offset int
+}
+
+// query encodes the filter as URL query parameters.
+func (f *WebhookMessageFilter) query(appID string) url.Values {
+	q := url.Values{}
+	q.Set("appId", appID)
+	if f == nil {
+		return q
+	}
+	if f.Delivered != nil {
+		q.Set("isDelivered", strconv.FormatBool(*f.Delivered))
+	}
+	if !f.Since.IsZero() {
+		q.Set("since", f.Since.UTC().Format(time.RFC3339Nano))
+	}
+	if !f.Until.IsZero() {
+		q.Set("until", f.Until.UTC().Format(time.RFC3339Nano))
+	}
+	if f.EventType != "" {
+		q.Set("eventType", f.EventType)
+	}
+	if f.Limit > 0 {
+		q.Set("limit", strconv.Itoa(f.Limit))
+	}
+	if f.Offset > 0 {
+		q.Set("offset", strconv.Itoa(f.Offset))
+	}
+	return q
+}
+
+// List returns the messages sent for an app, newest first, narrowed by
+// filter, which may be nil.
+// Example:
+//
+//	pending, err := client.WebhookMessage.List(ctx, "APP_ID", &vartiq.WebhookMessageFilter{
+//	    Delivered: vartiq.Ptr(false),
+//	    Since:     time.Now().Add(-time.Hour),
+//	})
+func (s *WebhookMessageService) List(ctx context.Context, appID string, filter *WebhookMessageFilter) (*Response[[]WebhookMessage], error) {
+	resp := &webhookMessageResponse{}
+	httpResp, err := s.client.newRequest(ctx).
+		SetQueryParamsFromValues(filter.query(appID)).
+		SetResult(resp).
+		Get("/webhook-messages")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook messages: %w", err)
+	}
+	if err := checkResponse(httpResp); err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, unsuccessful(httpResp, "webhook message list retrieval failed: "+resp.Message)
+	}
+
+	messages := make([]WebhookMessage, 0, len(resp.Data.WebhookMessages))
+	for i := range resp.Data.WebhookMessages {
+		message, err := resp.Data.WebhookMessages[i].decode()
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+
+	result := &Response[[]WebhookMessage]{
+		Data:    messages,
+		Message: resp.Message,
+		Success: resp.Success,
+	}
+	result.setMeta(httpResp)
+	return result, nil
+}
+
+// Get returns a single webhook message by ID.
+func (s *WebhookMessageService) Get(ctx context.Context, messageID string) (*Response[WebhookMessage], error) {
+	resp := &Response[webhookMessageWire]{}
+	httpResp, err := s.client.newRequest(ctx).
+		SetResult(resp).
+		Get("/webhook-messages/" + messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook message: %w", err)
+	}
+	if err := checkResponse(httpResp); err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, unsuccessful(httpResp, "webhook message retrieval failed: "+resp.Message)
+	}
+
+	message, err := resp.Data.decode()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Response[WebhookMessage]{
 		Data:    message,
 		Message: resp.Message,
 		Success: resp.Success,
-	}, nil
+	}
+	result.setMeta(httpResp)
+	return result, nil
+}
+
+// DeliveryAttempt is one attempt to deliver a webhook message to one of the
+// app's webhooks.
+type DeliveryAttempt struct {
+	ID        string
+	MessageID string
+	WebhookID string
+	URL       string
+	// Attempt numbers the attempts for the same message and webhook, from 1.
+	Attempt int
+	// StatusCode is the endpoint's HTTP status, or 0 when no response was
+	// received.
+	StatusCode int
+	// ResponseBody is the start of the endpoint's response body.
+	ResponseBody string
+	Latency      time.Duration
+	// Error describes why no response was received, e.g. a timeout or
+	// connection failure.
+	Error     string
+	Timestamp time.Time
+}
+
+// Succeeded reports whether the endpoint accepted the message with a 2xx status.
+func (a *DeliveryAttempt) Succeeded() bool {
+	return a.Error == "" && a.StatusCode >= 200 && a.StatusCode < 300
+}
+
+// deliveryAttemptWire is a delivery attempt as the API encodes it.
+type deliveryAttemptWire struct {
+	ID           string    `json:"id"`
+	MessageID    string    `json:"message"`
+	WebhookID    string    `json:"webhook"`
+	URL          string    `json:"url"`
+	Attempt      int       `json:"attempt"`
+	StatusCode   int       `json:"statusCode"`
+	ResponseBody string    `json:"responseBody"`
+	LatencyMs    int64     `json:"latencyMs"`
+	Error        string    `json:"error"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+func (a *deliveryAttemptWire) decode() DeliveryAttempt {
+	return DeliveryAttempt{
+		ID:           a.ID,
+		MessageID:    a.MessageID,
+		WebhookID:    a.WebhookID,
+		URL:          a.URL,
+		Attempt:      a.Attempt,
+		StatusCode:   a.StatusCode,
+		ResponseBody: a.ResponseBody,
+		Latency:      time.Duration(a.LatencyMs) * time.Millisecond,
+		Error:        a.Error,
+		Timestamp:    a.CreatedAt,
+	}
+}
+
+// ListAttempts returns the delivery attempts made for a message, oldest
+// first, across all of its webhooks.
+// Example:
+//
+//	attempts, err := client.WebhookMessage.ListAttempts(ctx, "MESSAGE_ID")
+//	for _, a := range attempts.Data {
+//	    if !a.Succeeded() {
+//	        log.Printf("attempt %d to %s: status %d %s", a.Attempt, a.URL, a.StatusCode, a.Error)
+//	    }
+//	}
+func (s *WebhookMessageService) ListAttempts(ctx context.Context, messageID string) (*Response[[]DeliveryAttempt], error) {
+	resp := &Response[struct {
+		Attempts []deliveryAttemptWire `json:"attempts"`
+	}]{}
+	httpResp, err := s.client.newRequest(ctx).
+		SetResult(resp).
+		Get("/webhook-messages/" + messageID + "/attempts")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list delivery attempts: %w", err)
+	}
+	if err := checkResponse(httpResp); err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, unsuccessful(httpResp, "delivery attempt list retrieval failed: "+resp.Message)
+	}
+
+	attempts := make([]DeliveryAttempt, 0, len(resp.Data.Attempts))
+	for i := range resp.Data.Attempts {
+		attempts = append(attempts, resp.Data.Attempts[i].decode())
+	}
+
+	result := &Response[[]DeliveryAttempt]{
+		Data:    attempts,
+		Message: resp.Message,
+		Success: resp.Success,
+	}
+	result.setMeta(httpResp)
+	return result, nil
+}
+
+// RetryMessageOptions selects which webhooks Retry redelivers a message to.
+type RetryMessageOptions struct {
+	// WebhookIDs limits redelivery to these webhooks. Empty means every
+	// webhook the message was sent to.
+	WebhookIDs []string `json:"webhookIds,omitempty"`
+}
+
+// RetryResult reports the webhooks a message was queued for redelivery to.
+type RetryResult struct {
+	MessageID  string   `json:"id"`
+	WebhookIDs []string `json:"webhookIds"`
+}
+
+// Retry queues a message for redelivery, to all of its webhooks or those in
+// opts, which may be nil. Delivery happens in the background; follow it with
+// ListAttempts. Like Create, every call carries an idempotency key.
+// Example:
+//
+//	_, err := client.WebhookMessage.Retry(ctx, "MESSAGE_ID", &vartiq.RetryMessageOptions{
+//	    WebhookIDs: []string{"WEBHOOK_ID"},
+//	})
+func (s *WebhookMessageService) Retry(ctx context.Context, messageID string, opts *RetryMessageOptions, reqOpts ...RequestOption) (*Response[RetryResult], error) {
+	if opts == nil {
+		opts = &RetryMessageOptions{}
+	}
+	reqOpts = append([]RequestOption{WithIdempotencyKey(NewIdempotencyKey())}, reqOpts...)
+
+	resp := &Response[RetryResult]{}
+	httpResp, err := s.client.newRequest(ctx, reqOpts...).
+		SetBody(opts).
+		SetResult(resp).
+		Post("/webhook-messages/" + messageID + "/retry")
+	if err != nil {
+		return nil, fmt.Errorf("failed to retry webhook message: %w", err)
+	}
+	if err := checkResponse(httpResp); err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, unsuccessful(httpResp, "webhook message retry failed: "+resp.Message)
+	}
+
+	resp.setMeta(httpResp)
+	return resp, nil
+}
+
+// replayPageSize is the number of messages Replay lists per request.
+const replayPageSize = 100
+
+// ReplayFilter narrows the messages Replay resends and receives its progress.
+type ReplayFilter struct {
+	// Delivered selects delivered (true) or undelivered (false) messages;
+	// nil resends both.
+	Delivered *bool
+	// EventType selects messages of one event type.
+	EventType string
+	// WebhookIDs limits redelivery to these webhooks, as in
+	// RetryMessageOptions.
+	WebhookIDs []string
+	// OnProgress, if set, is called after each message is processed.
+	OnProgress func(ReplayProgress)
+}
+
+// ReplayItem is the outcome of resending one message.
+type ReplayItem struct {
+	MessageID string
+	// WebhookIDs are the webhooks the message was queued for.
+	WebhookIDs []string
+	Err        error
+}
+
+// ReplayProgress describes how far a Replay has got.
+type ReplayProgress struct {
+	Done  int
+	Total int
+	// Last is the outcome of the message just processed.
+	Last ReplayItem
+}
+
+// ReplayResult summarizes a Replay.
+type ReplayResult struct {
+	// Items holds the outcome for every message processed, oldest first.
+	Items     []ReplayItem
+	Succeeded int
+	Failed    int
+}
+
+// Replay resends an app's messages created in [since, until), oldest
+// first, narrowed by filter, which may be nil. A message that cannot be
+// resent is recorded in the result without stopping the replay. The matching
+// messages are listed before any is resent, each page ending where the
+// previous one did by creation time rather than by offset, so messages
+// delivered or created while listing neither shift the pages nor cause any
+// to be skipped, and each is resent once. If ctx ends, Replay returns the
+// items processed so far together with ctx's error.
+// Example:
+//
+//	result, err := client.WebhookMessage.Replay(ctx, "APP_ID", outageStart, outageEnd, &vartiq.ReplayFilter{
+//	    Delivered:  vartiq.Ptr(false),
+//	    WebhookIDs: []string{"WEBHOOK_ID"},
+//	    OnProgress: func(p vartiq.ReplayProgress) { log.Printf("%d/%d", p.Done, p.Total) },
+//	})
+func (s *WebhookMessageService) Replay(ctx context.Context, appID string, since, until time.Time, filter *ReplayFilter) (*ReplayResult, error) {
+	if filter == nil {
+		filter = &ReplayFilter{}
+	}
+
+	var ids []string
+	// seen drops messages listed twice: each page reaches up to and
+	// including the millisecond of the previous page's oldest message, so
+	// that messages created in the same millisecond are not skipped.
+	seen := make(map[string]bool)
+	list := &WebhookMessageFilter{
+		Delivered: filter.Delivered,
+		Since:     since,
+		Until:     until,
+		EventType: filter.EventType,
+		Limit:     replayPageSize,
+	}
+	for {
+		page, err := s.List(ctx, appID, list)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list messages to replay: %w", err)
+		}
+		for _, m := range page.Data {
+			if !seen[m.ID] {
+				seen[m.ID] = true
+				ids = append(ids, m.ID)
+			}
+		}
+		if len(page.Data) < replayPageSize {
+			break
+		}
+
+		oldest := page.Data[len(page.Data)-1]
+		createdAt, err := time.Parse(time.RFC3339Nano, oldest.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list messages to replay: message %s has invalid createdAt %q", oldest.ID, oldest.CreatedAt)
+		}
+		next := createdAt.Truncate(time.Millisecond).Add(time.Millisecond)
+		if next.Equal(list.Until) {
+			// A whole page was created in one millisecond; only the offset
+			// can move past it.
+			list.Offset += len(page.Data)
+		} else {
+			list.Until, list.Offset = next, 0
+		}
+	}
+
+	// List returns newest first; resend in the order the messages were sent.
+	for i, j := 0, len(ids)-1; i < j; i, j = i+1, j-1 {
+		ids[i], ids[j] = ids[j], ids[i]
+	}
+
+	result := &ReplayResult{Items: make([]ReplayItem, 0, len(ids))}
+	opts := &RetryMessageOptions{WebhookIDs: filter.WebhookIDs}
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		item := ReplayItem{MessageID: id}
+		resp, err := s.Retry(ctx, id, opts)
+		if err != nil {
+			item.Err = err
+			result.Failed++
+		} else {
+			item.WebhookIDs = resp.Data.WebhookIDs
+			result.Succeeded++
+		}
+		result.Items = append(result.Items, item)
+
+		if filter.OnProgress != nil {
+			filter.OnProgress(ReplayProgress{Done: len(result.Items), Total: len(ids), Last: item})
+		}
+	}
+	return result, nil
+}
+
+// ErrDeliveryFailed is returned by WaitForDelivery when a message has used up
+// its delivery attempts without being delivered.
+var ErrDeliveryFailed = errors.New("vartiq: delivery failed")
+
+// DefaultWaitMaxAttempts is the number of failed delivery attempts after
+// which WaitForDelivery reports a message as permanently failed, unless
+// WaitOptions.MaxAttempts is set. The API does not report when it has given
+// up on a message, so WaitForDelivery counts the failed attempts instead.
+const DefaultWaitMaxAttempts = 5
+
+// WaitOptions configures WaitForDelivery.
+type WaitOptions struct {
+	// PollInterval is the delay before the second poll. It doubles after
+	// every poll up to MaxPollInterval. Defaults to 500ms.
+	PollInterval time.Duration
+	// MaxPollInterval caps the delay between polls. Defaults to 10s.
+	MaxPollInterval time.Duration
+	// MaxAttempts is the number of failed delivery attempts after which the
+	// message counts as permanently failed. Zero means
+	// DefaultWaitMaxAttempts; a negative value waits until the message is
+	// delivered or ctx ends.
+	MaxAttempts int
+}
+
+// DeliveryStatus is a message together with its delivery attempts.
+type DeliveryStatus struct {
+	Message  WebhookMessage
+	Attempts []DeliveryAttempt
+}
+
+// failedAttempts returns the number of attempts that did not succeed.
+func (d *DeliveryStatus) failedAttempts() int {
+	n := 0
+	for i := range d.Attempts {
+		if !d.Attempts[i].Succeeded() {
+			n++
+		}
+	}
+	return n
+}
+
+// WaitForDelivery polls a message until it is delivered, has failed
+// permanently, or ctx ends, and returns its final state. opts may be nil.
+// A message has failed permanently once it has as many failed attempts as
+// WaitOptions.MaxAttempts, DefaultWaitMaxAttempts by default. Permanent
+// failure is reported as ErrDeliveryFailed, and an ended ctx as ctx's error;
+// both come with the last state seen, if any.
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+//	defer cancel()
+//	status, err := client.WebhookMessage.WaitForDelivery(ctx, msg.Data.ID, nil)
+func (s *WebhookMessageService) WaitForDelivery(ctx context.Context, messageID string, opts *WaitOptions) (*DeliveryStatus, error) {
+	o := WaitOptions{PollInterval: 500 * time.Millisecond, MaxPollInterval: 10 * time.Second, MaxAttempts: DefaultWaitMaxAttempts}
+	if opts != nil {
+		if opts.PollInterval > 0 {
+			o.PollInterval = opts.PollInterval
+		}
+		if opts.MaxPollInterval > 0 {
+			o.MaxPollInterval = opts.MaxPollInterval
+		}
+		if opts.MaxAttempts != 0 {
+			o.MaxAttempts = opts.MaxAttempts
+		}
+	}
+
+	var last *DeliveryStatus
+	interval := o.PollInterval
+	for {
+		message, err := s.Get(ctx, messageID)
+		if err != nil {
+			return last, waitError(ctx, err)
+		}
+		attempts, err := s.ListAttempts(ctx, messageID)
+		if err != nil {
+			return last, waitError(ctx, err)
+		}
+		last = &DeliveryStatus{Message: message.Data, Attempts: attempts.Data}
+
+		if last.Message.IsDelivered {
+			return last, nil
+		}
+		if o.MaxAttempts > 0 && last.failedAttempts() >= o.MaxAttempts {
+			return last, fmt.Errorf("%w: %d failed attempts", ErrDeliveryFailed, last.failedAttempts())
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return last, ctx.Err()
+		case <-timer.C:
+		}
+		if interval *= 2; interval > o.MaxPollInterval {
+			interval = o.MaxPollInterval
+		}
+	}
+}
+
+// waitError reports ctx's error in place of a request error caused by ctx
+// ending, so callers can match it with errors.Is.
+func waitError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
 }
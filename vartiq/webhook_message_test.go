@@ -2,10 +2,21 @@ package vartiq
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Helper to create a WebhookMessageService with a mock client
@@ -27,3 +38,536 @@ func TestWebhookMessageService_Create(t *testing.T) {
 	assert.Error(t, err) // No server, should error
 	assert.Nil(t, message)
 }
+
+func TestWebhookMessageFilter_Query(t *testing.T) {
+	since := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	until := since.Add(time.Hour)
+
+	var nilFilter *WebhookMessageFilter
+	assert.Equal(t, url.Values{"appId": {"app-1"}}, nilFilter.query("app-1"))
+
+	q := (&WebhookMessageFilter{
+		Delivered: Ptr(false),
+		Since:     since,
+		Until:     until,
+		EventType: "invoice.paid",
+		Limit:     10,
+		Offset:    20,
+	}).query("app-1")
+	assert.Equal(t, url.Values{
+		"appId":       {"app-1"},
+		"isDelivered": {"false"},
+		"since":       {"2024-05-01T12:00:00Z"},
+		"until":       {"2024-05-01T13:00:00Z"},
+		"eventType":   {"invoice.paid"},
+		"limit":       {"10"},
+		"offset":      {"20"},
+	}, q)
+}
+
+func TestWebhookMessageService_List(t *testing.T) {
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/webhook-messages", r.URL.Path)
+		query = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true,"message":"ok","data":{"webhookMessages":[
+			{"id":"m1","app":"app-1","webhook":"wh-1","payload":"{\"type\":\"invoice.paid\",\"amount\":5}",
+			 "headers":[{"key":"X-Vartiq-Signature","value":"sig"}],"isDelivered":true},
+			{"id":"m2","app":"app-1","payload":{"type":"user.deleted"}}
+		]}}`))
+	}))
+	defer srv.Close()
+	client := NewClient("test-key", WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{}))
+
+	resp, err := client.WebhookMessage.List(context.Background(), "app-1", &WebhookMessageFilter{Delivered: Ptr(true)})
+	require.NoError(t, err)
+	assert.Equal(t, "app-1", query.Get("appId"))
+	assert.Equal(t, "true", query.Get("isDelivered"))
+
+	require.Len(t, resp.Data, 2)
+	assert.Equal(t, WebhookMessage{
+		ID:          "m1",
+		AppID:       "app-1",
+		WebhookID:   "wh-1",
+		Payload:     map[string]interface{}{"type": "invoice.paid", "amount": float64(5)},
+		RawPayload:  json.RawMessage(`{"type":"invoice.paid","amount":5}`),
+		Signature:   "sig",
+		IsDelivered: true,
+	}, resp.Data[0])
+	assert.Equal(t, map[string]interface{}{"type": "user.deleted"}, resp.Data[1].Payload)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestWebhookMessageService_Get(t *testing.T) {
+	client := newTestServer(t, http.StatusOK,
+		`{"success":true,"message":"ok","data":{"id":"m1","app":"app-1","payload":"\"hello\"","isDelivered":false}}`, nil)
+
+	resp, err := client.WebhookMessage.Get(context.Background(), "m1")
+	require.NoError(t, err)
+	assert.Equal(t, "m1", resp.Data.ID)
+	assert.Equal(t, "hello", resp.Data.Payload)
+	assert.False(t, resp.Data.IsDelivered)
+}
+
+func TestWebhookMessageService_GetErrors(t *testing.T) {
+	client := newTestServer(t, http.StatusNotFound, `{"success":false,"message":"Webhook message not found"}`, nil)
+	_, err := client.WebhookMessage.Get(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	client = newTestServer(t, http.StatusOK, `{"success":true,"data":{"id":"m1","payload":"{not json"}}`, nil)
+	_, err = client.WebhookMessage.Get(context.Background(), "m1")
+	assert.ErrorContains(t, err, "failed to parse payload")
+}
+
+func TestWebhookMessageService_ListAttempts(t *testing.T) {
+	client := newTestServer(t, http.StatusOK, `{"success":true,"message":"ok","data":{"attempts":[
+		{"id":"a1","message":"m1","webhook":"wh-1","url":"https://example.com/hook","attempt":1,
+		 "statusCode":503,"responseBody":"unavailable","latencyMs":120,"createdAt":"2024-05-01T12:00:00.000Z"},
+		{"id":"a2","message":"m1","webhook":"wh-1","url":"https://example.com/hook","attempt":2,
+		 "error":"dial tcp: connection refused","createdAt":"2024-05-01T12:01:00.000Z"},
+		{"id":"a3","message":"m1","webhook":"wh-1","url":"https://example.com/hook","attempt":3,
+		 "statusCode":200,"latencyMs":40,"createdAt":"2024-05-01T12:05:00.000Z"}
+	]}}`, nil)
+
+	resp, err := client.WebhookMessage.ListAttempts(context.Background(), "m1")
+	require.NoError(t, err)
+	require.Len(t, resp.Data, 3)
+
+	first := resp.Data[0]
+	assert.Equal(t, DeliveryAttempt{
+		ID:           "a1",
+		MessageID:    "m1",
+		WebhookID:    "wh-1",
+		URL:          "https://example.com/hook",
+		Attempt:      1,
+		StatusCode:   503,
+		ResponseBody: "unavailable",
+		Latency:      120 * time.Millisecond,
+		Timestamp:    time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}, first)
+	assert.False(t, first.Succeeded())
+	assert.False(t, resp.Data[1].Succeeded())
+	assert.Equal(t, "dial tcp: connection refused", resp.Data[1].Error)
+	assert.True(t, resp.Data[2].Succeeded())
+}
+
+func TestWebhookMessageService_Retry(t *testing.T) {
+	var (
+		body []byte
+		key  string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/webhook-messages/m1/retry", r.URL.Path)
+		body, _ = io.ReadAll(r.Body)
+		key = r.Header.Get(IdempotencyKeyHeader)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true,"message":"queued","data":{"id":"m1","webhookIds":["wh-1"]}}`))
+	}))
+	defer srv.Close()
+	client := NewClient("test-key", WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{}))
+
+	resp, err := client.WebhookMessage.Retry(context.Background(), "m1", &RetryMessageOptions{WebhookIDs: []string{"wh-1"}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"webhookIds":["wh-1"]}`, string(body))
+	assert.NotEmpty(t, key)
+	assert.Equal(t, RetryResult{MessageID: "m1", WebhookIDs: []string{"wh-1"}}, resp.Data)
+
+	_, err = client.WebhookMessage.Retry(context.Background(), "m1", nil, WithIdempotencyKey("manual"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(body))
+	assert.Equal(t, "manual", key)
+}
+
+func TestWebhookMessageService_Replay(t *testing.T) {
+	// Five messages m1..m5, oldest first; m3 cannot be redelivered.
+	var (
+		mu      sync.Mutex
+		queries []url.Values
+		retried []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			q := r.URL.Query()
+			queries = append(queries, q)
+			offset, _ := strconv.Atoi(q.Get("offset"))
+			var page []map[string]interface{}
+			for i := 5 - offset; i >= 1 && len(page) < replayPageSize; i-- {
+				page = append(page, map[string]interface{}{"id": "m" + strconv.Itoa(i), "payload": "{}"})
+			}
+			body, _ := json.Marshal(map[string]interface{}{
+				"success": true, "data": map[string]interface{}{"webhookMessages": page},
+			})
+			_, _ = w.Write(body)
+			return
+		}
+		id := strings.Split(r.URL.Path, "/")[2]
+		retried = append(retried, id)
+		if id == "m3" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"success":false,"message":"Webhook not found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"success":true,"data":{"id":"` + id + `","webhookIds":["wh-1"]}}`))
+	}))
+	defer srv.Close()
+	client := NewClient("test-key", WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{}))
+
+	since := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var progress []ReplayProgress
+	result, err := client.WebhookMessage.Replay(context.Background(), "app-1", since, since.Add(time.Hour), &ReplayFilter{
+		Delivered:  Ptr(false),
+		EventType:  "invoice.paid",
+		OnProgress: func(p ReplayProgress) { progress = append(progress, p) },
+	})
+	require.NoError(t, err)
+
+	require.Len(t, queries, 1)
+	assert.Equal(t, "false", queries[0].Get("isDelivered"))
+	assert.Equal(t, "invoice.paid", queries[0].Get("eventType"))
+	assert.Equal(t, "2024-05-01T12:00:00Z", queries[0].Get("since"))
+	assert.Equal(t, "2024-05-01T13:00:00Z", queries[0].Get("until"))
+
+	assert.Equal(t, []string{"m1", "m2", "m3", "m4", "m5"}, retried)
+	assert.Equal(t, 4, result.Succeeded)
+	assert.Equal(t, 1, result.Failed)
+	require.Len(t, result.Items, 5)
+	assert.Equal(t, []string{"wh-1"}, result.Items[0].WebhookIDs)
+	assert.ErrorIs(t, result.Items[2].Err, ErrNotFound)
+
+	require.Len(t, progress, 5)
+	assert.Equal(t, ReplayProgress{Done: 5, Total: 5, Last: result.Items[4]}, progress[4])
+}
+
+// replayStore serves a changing list of messages, honoring the filters
+// Replay uses, and records the messages resent.
+type replayStore struct {
+	mu       sync.Mutex
+	messages []replayMessage // oldest first
+	lists    int
+	// onList is called after each list request is served.
+	onList  func(s *replayStore)
+	retried []string
+}
+
+type replayMessage struct {
+	id        string
+	created   time.Time
+	delivered bool
+}
+
+func (s *replayStore) add(id string, created time.Time) {
+	s.messages = append(s.messages, replayMessage{id: id, created: created})
+}
+
+func (s *replayStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method != http.MethodGet {
+		id := strings.Split(r.URL.Path, "/")[2]
+		s.retried = append(s.retried, id)
+		_, _ = w.Write([]byte(`{"success":true,"data":{"id":"` + id + `","webhookIds":["wh-1"]}}`))
+		return
+	}
+
+	q := r.URL.Query()
+	until, _ := time.Parse(time.RFC3339Nano, q.Get("until"))
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	page := []map[string]interface{}{}
+	for i := len(s.messages) - 1; i >= 0 && len(page) < limit; i-- {
+		m := s.messages[i]
+		if (q.Get("isDelivered") == "false" && m.delivered) || (!until.IsZero() && !m.created.Before(until)) {
+			continue
+		}
+		if offset > 0 {
+			offset--
+			continue
+		}
+		page = append(page, map[string]interface{}{
+			"id": m.id, "payload": "{}", "createdAt": m.created.Format("2006-01-02T15:04:05.000Z"),
+		})
+	}
+	s.lists++
+	if s.onList != nil {
+		s.onList(s)
+	}
+	body, _ := json.Marshal(map[string]interface{}{
+		"success": true, "data": map[string]interface{}{"webhookMessages": page},
+	})
+	_, _ = w.Write(body)
+}
+
+// assertResentOnce checks that want were resent once each, oldest first.
+func (s *replayStore) assertResentOnce(t *testing.T, want []string) {
+	t.Helper()
+	counts := map[string]int{}
+	for _, id := range s.retried {
+		counts[id]++
+	}
+	for _, id := range want {
+		assert.Equal(t, 1, counts[id], "%s resent %d times", id, counts[id])
+	}
+	assert.Len(t, s.retried, len(want))
+	assert.Equal(t, want[0], s.retried[0])
+}
+
+func TestWebhookMessageService_ReplayChangesWhileListing(t *testing.T) {
+	// replayPageSize+2 undelivered messages a second apart, except that m2
+	// and m3 share a millisecond across the first page boundary.
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := &replayStore{}
+	var want []string
+	for i := 1; i <= replayPageSize+2; i++ {
+		created := base.Add(time.Duration(i) * time.Second)
+		if i == 2 {
+			created = base.Add(3 * time.Second)
+		}
+		id := "m" + strconv.Itoa(i)
+		store.add(id, created)
+		want = append(want, id)
+	}
+	// While the first page is listed, the server delivers its two newest
+	// messages and a new one is created, which would shift offset-based
+	// pages by one.
+	store.onList = func(s *replayStore) {
+		if s.lists == 1 {
+			s.messages[len(s.messages)-1].delivered = true
+			s.messages[len(s.messages)-2].delivered = true
+			s.add("new", base.Add(time.Hour))
+		}
+	}
+	srv := httptest.NewServer(store)
+	defer srv.Close()
+	client := NewClient("test-key", WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{}))
+
+	result, err := client.WebhookMessage.Replay(context.Background(), "app-1", time.Time{}, time.Time{}, &ReplayFilter{Delivered: Ptr(false)})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, store.lists)
+	assert.Equal(t, replayPageSize+2, result.Succeeded)
+	store.assertResentOnce(t, want)
+}
+
+func TestWebhookMessageService_ReplaySameMillisecond(t *testing.T) {
+	// More messages than fit on a page, all created in one millisecond.
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := &replayStore{}
+	var want []string
+	for i := 1; i <= replayPageSize*2+1; i++ {
+		id := "m" + strconv.Itoa(i)
+		store.add(id, created)
+		want = append(want, id)
+	}
+	srv := httptest.NewServer(store)
+	defer srv.Close()
+	client := NewClient("test-key", WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{}))
+
+	result, err := client.WebhookMessage.Replay(context.Background(), "app-1", time.Time{}, time.Time{}, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, replayPageSize*2+1, result.Succeeded)
+	store.assertResentOnce(t, want)
+}
+
+func TestWebhookMessageService_ReplayCanceled(t *testing.T) {
+	client := newTestServer(t, http.StatusOK,
+		`{"success":true,"data":{"webhookMessages":[{"id":"m2","payload":"{}"},{"id":"m1","payload":"{}"}]}}`, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	result, err := client.WebhookMessage.Replay(ctx, "app-1", time.Time{}, time.Time{}, &ReplayFilter{
+		OnProgress: func(ReplayProgress) { cancel() },
+	})
+	assert.True(t, errors.Is(err, context.Canceled))
+	require.Len(t, result.Items, 1)
+	assert.Equal(t, "m1", result.Items[0].MessageID)
+}
+
+// newDeliveryServer serves Get and ListAttempts for message m1. Each poll
+// adds one failed attempt until the message is delivered on poll deliverOn,
+// or never when deliverOn is 0.
+func newDeliveryServer(t *testing.T, deliverOn int) (*Client, *int) {
+	var (
+		mu    sync.Mutex
+		polls int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		delivered := deliverOn > 0 && polls >= deliverOn
+		if strings.HasSuffix(r.URL.Path, "/attempts") {
+			var attempts []map[string]interface{}
+			for i := 1; i <= polls; i++ {
+				status := http.StatusBadGateway
+				if delivered && i == polls {
+					status = http.StatusOK
+				}
+				attempts = append(attempts, map[string]interface{}{"id": "a" + strconv.Itoa(i), "attempt": i, "statusCode": status})
+			}
+			body, _ := json.Marshal(map[string]interface{}{"success": true, "data": map[string]interface{}{"attempts": attempts}})
+			_, _ = w.Write(body)
+			return
+		}
+		polls++
+		delivered = deliverOn > 0 && polls >= deliverOn
+		_, _ = w.Write([]byte(`{"success":true,"data":{"id":"m1","payload":"{}","isDelivered":` + strconv.FormatBool(delivered) + `}}`))
+	}))
+	t.Cleanup(srv.Close)
+	return NewClient("test-key", WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{})), &polls
+}
+
+func TestWebhookMessageService_WaitForDelivery(t *testing.T) {
+	opts := &WaitOptions{PollInterval: time.Millisecond, MaxPollInterval: 2 * time.Millisecond}
+
+	t.Run("delivered", func(t *testing.T) {
+		client, polls := newDeliveryServer(t, 3)
+		status, err := client.WebhookMessage.WaitForDelivery(context.Background(), "m1", opts)
+		require.NoError(t, err)
+		assert.Equal(t, 3, *polls)
+		assert.True(t, status.Message.IsDelivered)
+		require.Len(t, status.Attempts, 3)
+		assert.True(t, status.Attempts[2].Succeeded())
+	})
+
+	t.Run("failed permanently", func(t *testing.T) {
+		client, _ := newDeliveryServer(t, 0)
+		status, err := client.WebhookMessage.WaitForDelivery(context.Background(), "m1", &WaitOptions{
+			PollInterval: time.Millisecond,
+			MaxAttempts:  2,
+		})
+		assert.ErrorIs(t, err, ErrDeliveryFailed)
+		require.NotNil(t, status)
+		assert.False(t, status.Message.IsDelivered)
+		assert.Len(t, status.Attempts, 2)
+	})
+
+	t.Run("failed permanently by default", func(t *testing.T) {
+		client, _ := newDeliveryServer(t, 0)
+		status, err := client.WebhookMessage.WaitForDelivery(context.Background(), "m1", opts)
+		assert.ErrorIs(t, err, ErrDeliveryFailed)
+		require.NotNil(t, status)
+		assert.Len(t, status.Attempts, DefaultWaitMaxAttempts)
+	})
+
+	t.Run("context expires", func(t *testing.T) {
+		client, _ := newDeliveryServer(t, 0)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		status, err := client.WebhookMessage.WaitForDelivery(ctx, "m1", &WaitOptions{
+			PollInterval:    time.Millisecond,
+			MaxPollInterval: 2 * time.Millisecond,
+			MaxAttempts:     -1,
+		})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		require.NotNil(t, status)
+		assert.False(t, status.Message.IsDelivered)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		client := newTestServer(t, http.StatusNotFound, `{"success":false,"message":"Webhook message not found"}`, nil)
+		status, err := client.WebhookMessage.WaitForDelivery(context.Background(), "missing", opts)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.Nil(t, status)
+	})
+}
+
+func TestWebhookMessageService_CreateBatch(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		keys  []string
+		sizes []int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, "/webhook-messages/batch", r.URL.Path)
+		var req struct {
+			AppID    string `json:"appId"`
+			Messages []struct {
+				Payload map[string]int `json:"payload"`
+			} `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "app-1", req.AppID)
+		keys = append(keys, r.Header.Get(IdempotencyKeyHeader))
+		sizes = append(sizes, len(req.Messages))
+
+		w.Header().Set("Content-Type", "application/json")
+		if len(keys) == 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"success":false,"message":"overloaded"}`))
+			return
+		}
+		var results []map[string]interface{}
+		for _, m := range req.Messages {
+			n := m.Payload["n"]
+			if n == 7 {
+				results = append(results, map[string]interface{}{
+					"error": map[string]interface{}{"code": 400, "message": "Validation failed",
+						"errors": []map[string]string{{"field": "payload", "message": "is invalid"}}},
+				})
+				continue
+			}
+			payload, _ := json.Marshal(m.Payload)
+			results = append(results, map[string]interface{}{"webhookMessages": []map[string]interface{}{
+				{"id": "m" + strconv.Itoa(n), "payload": string(payload)},
+			}})
+		}
+		body, _ := json.Marshal(map[string]interface{}{"success": true, "data": map[string]interface{}{"results": results}})
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+	client := NewClient("test-key", WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{}))
+
+	payloads := make([]interface{}, 2*MaxBatchSize+50)
+	for i := range payloads {
+		payloads[i] = map[string]int{"n": i}
+	}
+	results, err := client.WebhookMessage.CreateBatch(context.Background(), "app-1", payloads, WithIdempotencyKey("evt"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{MaxBatchSize, MaxBatchSize, 50}, sizes)
+	assert.Equal(t, []string{"evt-0", "evt-1", "evt-2"}, keys)
+	require.Len(t, results, len(payloads))
+	for i, r := range results {
+		assert.Equal(t, i, r.Index)
+	}
+
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, "m0", results[0].Message.ID)
+	assert.Equal(t, "app-1", results[0].Message.AppID)
+	assert.Equal(t, map[string]interface{}{"n": float64(0)}, results[0].Message.Payload)
+
+	var verr *ValidationError
+	require.ErrorAs(t, results[7].Err, &verr)
+	assert.Equal(t, []FieldError{{Field: "payload", Message: "is invalid"}}, verr.Fields)
+	assert.False(t, results[7].RequestFailed)
+
+	// The second chunk failed as a whole; the third still went through.
+	assert.ErrorIs(t, results[MaxBatchSize].Err, ErrServer)
+	assert.ErrorIs(t, results[2*MaxBatchSize-1].Err, ErrServer)
+	assert.True(t, results[MaxBatchSize].RequestFailed)
+	assert.True(t, results[2*MaxBatchSize-1].RequestFailed)
+	assert.NoError(t, results[2*MaxBatchSize].Err)
+	assert.Equal(t, "m"+strconv.Itoa(2*MaxBatchSize), results[2*MaxBatchSize].Message.ID)
+}
+
+func TestWebhookMessageService_CreateBatchCanceled(t *testing.T) {
+	client := newTestServer(t, http.StatusOK, `{"success":true,"data":{"results":[]}}`, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results, err := client.WebhookMessage.CreateBatch(ctx, "app-1", []interface{}{"a", "b"})
+	assert.ErrorIs(t, err, context.Canceled)
+	require.Len(t, results, 2)
+	assert.Error(t, results[1].Err)
+	assert.True(t, results[1].RequestFailed)
+}
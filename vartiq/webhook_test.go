@@ -124,6 +124,7 @@ func TestWebhookAuth_Validation(t *testing.T) {
 			} else {
 				assert.Error(t, err)
 				assert.Equal(t, tt.expectedError, err.Error())
+				assert.ErrorIs(t, err, ErrValidation)
 			}
 		})
 	}
@@ -213,6 +214,73 @@ func TestWebhookService_Update(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestWebhookService_UpdateWebhook(t *testing.T) {
+	ws, _ := newMockWebhookService()
+	ctx := context.Background()
+	_, err := ws.UpdateWebhook(ctx, "webhookId", &UpdateWebhookRequest{URL: Ptr("http://example.com/new")})
+	assert.Error(t, err)
+}
+
+func TestUpdateWebhookRequest_Validation(t *testing.T) {
+	tests := []struct {
+		name          string
+		request       *UpdateWebhookRequest
+		expectedError string
+	}{
+		{
+			name:    "URL only",
+			request: &UpdateWebhookRequest{URL: Ptr("http://example.com")},
+		},
+		{
+			name:          "Empty URL",
+			request:       &UpdateWebhookRequest{URL: Ptr("")},
+			expectedError: "url must not be empty",
+		},
+		{
+			name:    "Clear custom headers",
+			request: &UpdateWebhookRequest{CustomHeaders: &[]Header{}},
+		},
+		{
+			name: "Valid switch to HMAC",
+			request: &UpdateWebhookRequest{
+				AuthMethod: Ptr(string(AuthMethodHMAC)),
+				HMACHeader: Ptr("x-Vartiq-signature"),
+				HMACSecret: Ptr("secret123"),
+			},
+		},
+		{
+			name: "Incomplete basic auth",
+			request: &UpdateWebhookRequest{
+				AuthMethod: Ptr(string(AuthMethodBasic)),
+				UserName:   Ptr("user"),
+			},
+			expectedError: "for basic auth, userName and password are required",
+		},
+		{
+			name:          "Credentials without auth method",
+			request:       &UpdateWebhookRequest{Password: Ptr("pass")},
+			expectedError: "authMethod is required when changing webhook credentials",
+		},
+		{
+			name:          "Invalid auth method",
+			request:       &UpdateWebhookRequest{AuthMethod: Ptr("token")},
+			expectedError: "invalid auth method: token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.validate()
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expectedError)
+				assert.ErrorIs(t, err, ErrValidation)
+			}
+		})
+	}
+}
+
 func TestWebhookService_Delete(t *testing.T) {
 	ws, _ := newMockWebhookService()
 	ctx := context.Background()
@@ -285,7 +353,7 @@ func TestWebhookService_Verify(t *testing.T) {
 			verifiedPayload, err := client.Verify(tt.payload, tt.signature, tt.secret)
 
 			if tt.expectedError {
-				assert.Error(t, err)
+				assert.ErrorIs(t, err, ErrSignatureInvalid)
 				assert.Contains(t, err.Error(), tt.expectedErrorMessage)
 				assert.Nil(t, verifiedPayload)
 			} else {
@@ -0,0 +1,96 @@
+package vartiqmock
+
+import "github.com/vartiqhq/vartiq-go-sdk/vartiq"
+
+// Client is a mock implementation of vartiq.API. Service mocks left nil are
+// replaced by empty ones, so any call to them panics with a clear message.
+type Client struct {
+	ProjectMock        *ProjectAPIMock
+	AppMock            *AppAPIMock
+	WebhookMock        *WebhookAPIMock
+	WebhookMessageMock *WebhookMessageAPIMock
+	EventTypeMock      *EventTypeAPIMock
+
+	// VerifyFunc mocks the Verify method.
+	VerifyFunc func(payload []byte, signature, secret string) ([]byte, error)
+	// VerifyWithOptionsFunc mocks the VerifyWithOptions method.
+	VerifyWithOptionsFunc func(payload []byte, signature, secret string, opts vartiq.VerifyOptions) ([]byte, error)
+	// VerifyStandardFunc mocks the VerifyStandard method.
+	VerifyStandardFunc func(payload []byte, signature, secret string, opts vartiq.VerifyOptions) ([]byte, error)
+	// InvalidateSchemasFunc mocks the InvalidateSchemas method.
+	InvalidateSchemasFunc func(appID string)
+}
+
+var _ vartiq.API = (*Client)(nil)
+
+// Projects returns ProjectMock.
+func (c *Client) Projects() vartiq.ProjectAPI {
+	if c.ProjectMock == nil {
+		return &ProjectAPIMock{}
+	}
+	return c.ProjectMock
+}
+
+// Apps returns AppMock.
+func (c *Client) Apps() vartiq.AppAPI {
+	if c.AppMock == nil {
+		return &AppAPIMock{}
+	}
+	return c.AppMock
+}
+
+// Webhooks returns WebhookMock.
+func (c *Client) Webhooks() vartiq.WebhookAPI {
+	if c.WebhookMock == nil {
+		return &WebhookAPIMock{}
+	}
+	return c.WebhookMock
+}
+
+// WebhookMessages returns WebhookMessageMock.
+func (c *Client) WebhookMessages() vartiq.WebhookMessageAPI {
+	if c.WebhookMessageMock == nil {
+		return &WebhookMessageAPIMock{}
+	}
+	return c.WebhookMessageMock
+}
+
+// EventTypes returns EventTypeMock.
+func (c *Client) EventTypes() vartiq.EventTypeAPI {
+	if c.EventTypeMock == nil {
+		return &EventTypeAPIMock{}
+	}
+	return c.EventTypeMock
+}
+
+// Verify calls VerifyFunc.
+func (c *Client) Verify(payload []byte, signature, secret string) ([]byte, error) {
+	if c.VerifyFunc == nil {
+		panic("vartiqmock: Client.VerifyFunc: method is nil but API.Verify was just called")
+	}
+	return c.VerifyFunc(payload, signature, secret)
+}
+
+// VerifyWithOptions calls VerifyWithOptionsFunc.
+func (c *Client) VerifyWithOptions(payload []byte, signature, secret string, opts vartiq.VerifyOptions) ([]byte, error) {
+	if c.VerifyWithOptionsFunc == nil {
+		panic("vartiqmock: Client.VerifyWithOptionsFunc: method is nil but API.VerifyWithOptions was just called")
+	}
+	return c.VerifyWithOptionsFunc(payload, signature, secret, opts)
+}
+
+// VerifyStandard calls VerifyStandardFunc.
+func (c *Client) VerifyStandard(payload []byte, signature, secret string, opts vartiq.VerifyOptions) ([]byte, error) {
+	if c.VerifyStandardFunc == nil {
+		panic("vartiqmock: Client.VerifyStandardFunc: method is nil but API.VerifyStandard was just called")
+	}
+	return c.VerifyStandardFunc(payload, signature, secret, opts)
+}
+
+// InvalidateSchemas calls InvalidateSchemasFunc.
+func (c *Client) InvalidateSchemas(appID string) {
+	if c.InvalidateSchemasFunc == nil {
+		panic("vartiqmock: Client.InvalidateSchemasFunc: method is nil but API.InvalidateSchemas was just called")
+	}
+	c.InvalidateSchemasFunc(appID)
+}
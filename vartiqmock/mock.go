@@ -0,0 +1,958 @@
+// Package vartiqmock provides mock implementations of the vartiq service
+// interfaces for unit tests.
+//
+// Each mock has one Func field per method. Set the ones the code under test
+// calls; calling a method whose Func is nil panics. Every call is recorded
+// and can be inspected with the matching <Method>Calls accessor.
+//
+//	projects := &vartiqmock.ProjectAPIMock{
+//	    GetFunc: func(ctx context.Context, projectID string) (*vartiq.Response[vartiq.Project], error) {
+//	        return &vartiq.Response[vartiq.Project]{Data: vartiq.Project{ID: projectID}}, nil
+//	    },
+//	}
+//	client := &vartiqmock.Client{ProjectMock: projects}
+package vartiqmock
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+)
+
+// ProjectAPIMock is a mock implementation of vartiq.ProjectAPI.
+type ProjectAPIMock struct {
+	// CreateFunc mocks the Create method.
+	CreateFunc func(ctx context.Context, req *vartiq.CreateProjectRequest, opts ...vartiq.RequestOption) (*vartiq.Response[vartiq.Project], error)
+
+	// ListFunc mocks the List method.
+	ListFunc func(ctx context.Context) (*vartiq.Response[[]vartiq.Project], error)
+
+	// GetFunc mocks the Get method.
+	GetFunc func(ctx context.Context, projectID string) (*vartiq.Response[vartiq.Project], error)
+
+	// UpdateFunc mocks the Update method.
+	UpdateFunc func(ctx context.Context, projectID string, req *vartiq.UpdateProjectRequest) (*vartiq.Response[vartiq.Project], error)
+
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(ctx context.Context, projectID string) error
+
+	mu    sync.Mutex
+	calls struct {
+		Create []struct {
+			Ctx  context.Context
+			Req  *vartiq.CreateProjectRequest
+			Opts []vartiq.RequestOption
+		}
+		List []struct {
+			Ctx context.Context
+		}
+		Get []struct {
+			Ctx       context.Context
+			ProjectID string
+		}
+		Update []struct {
+			Ctx       context.Context
+			ProjectID string
+			Req       *vartiq.UpdateProjectRequest
+		}
+		Delete []struct {
+			Ctx       context.Context
+			ProjectID string
+		}
+	}
+}
+
+var _ vartiq.ProjectAPI = (*ProjectAPIMock)(nil)
+
+// Create calls CreateFunc.
+func (m *ProjectAPIMock) Create(ctx context.Context, req *vartiq.CreateProjectRequest, opts ...vartiq.RequestOption) (*vartiq.Response[vartiq.Project], error) {
+	if m.CreateFunc == nil {
+		panic("vartiqmock: ProjectAPIMock.CreateFunc: method is nil but ProjectAPI.Create was just called")
+	}
+	m.mu.Lock()
+	m.calls.Create = append(m.calls.Create, struct {
+		Ctx  context.Context
+		Req  *vartiq.CreateProjectRequest
+		Opts []vartiq.RequestOption
+	}{Ctx: ctx, Req: req, Opts: opts})
+	m.mu.Unlock()
+	return m.CreateFunc(ctx, req, opts...)
+}
+
+// CreateCalls returns the arguments of every call to Create.
+func (m *ProjectAPIMock) CreateCalls() []struct {
+	Ctx  context.Context
+	Req  *vartiq.CreateProjectRequest
+	Opts []vartiq.RequestOption
+} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls.Create
+}
+
+// List calls ListFunc.
+func (m *ProjectAPIMock) List(ctx context.Context) (*vartiq.Response[[]vartiq.Project], error) {
+	if m.ListFunc == nil {
+		panic("vartiqmock: ProjectAPIMock.ListFunc: method is nil but ProjectAPI.List was just called")
+	}
+	m.mu.Lock()
+	m.calls.List = append(m.calls.List, struct {
+		Ctx context.Context
+	}{Ctx: ctx})
+	m.mu.Unlock()
+	return m.ListFunc(ctx)
+}
+
+// ListCalls returns the arguments of every call to List.
+func (m *ProjectAPIMock) ListCalls() []struct {
+	Ctx context.Context
+} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls.List
+}
+
+// Get calls GetFunc.
+func (m *ProjectAPIMock) Get(ctx context.Context, projectID string) (*vartiq.Response[vartiq.Project], error) {
+	if m.GetFunc == nil {
+		panic("vartiqmock: ProjectAPIMock.GetFunc: method is nil but ProjectAPI.Get was just called")
+	}
+	m.mu.Lock()
+	m.calls.Get = append(m.calls.Get, struct {
+		Ctx       context.Context
+		ProjectID string
+	}{Ctx: ctx, ProjectID: projectID})
+	m.mu.Unlock()
+	return m.GetFunc(ctx, projectID)
+}
+
+// GetCalls returns the arguments of every call to Get.
+func (m *ProjectAPIMock) GetCalls() []struct {
+	Ctx       context.Context
+	ProjectID string
+} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls.Get
+}
+
+// Update calls UpdateFunc.
+func (m *ProjectAPIMock) Update(ctx context.Context, projectID string, req *vartiq.UpdateProjectRequest) (*vartiq.Response[vartiq.Project], error) {
+	if m.UpdateFunc == nil {
+		panic("vartiqmock: ProjectAPIMock.UpdateFunc: method is nil but ProjectAPI.Update was just called")
+	}
+	m.mu.Lock()
+	m.calls.Update = append(m.calls.Update, struct {
+		Ctx       context.Context
+		ProjectID string
+		Req       *vartiq.UpdateProjectRequest
+	}{Ctx: ctx, ProjectID: projectID, Req: req})
+	m.mu.Unlock()
+	return m.UpdateFunc(ctx, projectID, req)
+}
+
+// UpdateCalls returns the arguments of every call to Update.
+func (m *ProjectAPIMock) UpdateCalls() []struct {
+	Ctx       context.Context
+	ProjectID string
+	Req       *vartiq.UpdateProjectRequest
+} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls.Update
+}
+
+// Delete calls DeleteFunc.
+func (m *ProjectAPIMock) Delete(ctx context.Context, projectID string) error {
+	if m.DeleteFunc == nil {
+		panic("vartiqmock: ProjectAPIMock.DeleteFunc: method is nil but ProjectAPI.Delete was just called")
+	}
+	m.mu.Lock()
+	m.calls.Delete = append(m.calls.Delete, struct {
+		Ctx       context.Context
+		ProjectID string
+	}{Ctx: ctx, ProjectID: projectID})
+	m.mu.Unlock()
+	return m.DeleteFunc(ctx, projectID)
+}
+
+// DeleteCalls returns the arguments of every call to Delete.
+func (m *ProjectAPIMock) DeleteCalls() []struct {
+	Ctx       context.Context
+	ProjectID string
+} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls.Delete
+}
+
+// AppAPIMock is a mock implementation of vartiq.AppAPI.
+type AppAPIMock struct {
+	// CreateFunc mocks the Create method.
+	CreateFunc func(ctx context.Context, req *vartiq.CreateAppRequest, opts ...vartiq.RequestOption) (*vartiq.Response[vartiq.App], error)
+
+	// ListFunc mocks the List method.
+	ListFunc func(ctx context.Context, projectID string) (*vartiq.Response[[]vartiq.App], error)
+
+	// GetFunc mocks the Get method.
+	GetFunc func(ctx context.Context, appID string) (*vartiq.Response[vartiq.App], error)
+
+	// UpdateFunc mocks the Update method.
+	UpdateFunc func(ctx context.Context, appID string, req *vartiq.UpdateAppRequest) (*vartiq.Response[vartiq.App], error)
+
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(ctx context.Context, appID string) error
+
+	mu    sync.Mutex
+	calls struct {
+		Create []struct {
+			Ctx  context.Context
+			Req  *vartiq.CreateAppRequest
+			Opts []vartiq.RequestOption
+		}
+		List []struct {
+			Ctx       context.Context
+			ProjectID string
+		}
+		Get []struct {
+			Ctx   context.Context
+			AppID string
+		}
+		Update []struct {
+			Ctx   context.Context
+			AppID string
+			Req   *vartiq.UpdateAppRequest
+		}
+		Delete []struct {
+			Ctx   context.Context
+			AppID string
+		}
+	}
+}
+
+var _ vartiq.AppAPI = (*AppAPIMock)(nil)
+
+// Create calls CreateFunc.
+func (m *AppAPIMock) Create(ctx context.Context, req *vartiq.CreateAppRequest, opts ...vartiq.RequestOption) (*vartiq.Response[vartiq.App], error) {
+	if m.CreateFunc == nil {
+		panic("vartiqmock: AppAPIMock.CreateFunc: method is nil but AppAPI.Create was just called")
+	}
+	m.mu.Lock()
+	m.calls.Create = append(m.calls.Create, struct {
+		Ctx  context.Context
+		Req  *vartiq.CreateAppRequest
+		Opts []vartiq.RequestOption
+	}{Ctx: ctx, Req: req, Opts: opts})
+	m.mu.Unlock()
+	return m.CreateFunc(ctx, req, opts...)
+}
+
+// CreateCalls returns the arguments of every call to Create.
+func (m *AppAPIMock) CreateCalls() []struct {
+	Ctx  context.Context
+	Req  *vartiq.CreateAppRequest
+	Opts []vartiq.RequestOption
+} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls.Create
+}
+
+// List calls ListFunc.
+func (m *AppAPIMock) List(ctx context.Context, projectID string) (*vartiq.Response[[]vartiq.App], error) {
+	if m.ListFunc == nil {
+		panic("vartiqmock: AppAPIMock.ListFunc: method is nil but AppAPI.List was just called")
+	}
+	m.mu.Lock()
+	m.calls.List = append(m.calls.List, struct {
+		Ctx       context.Context
+		ProjectID string
+	}{Ctx: ctx, ProjectID: projectID})
+	m.mu.Unlock()
+	return m.ListFunc(ctx, projectID)
+}
+
+// ListCalls returns the arguments of every call to List.
+func (m *AppAPIMock) ListCalls() []struct {
+	Ctx       context.Context
+	ProjectID string
+} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls.List
+}
+
+// Get calls GetFunc.
+func (m *AppAPIMock) Get(ctx context.Context, appID string) (*vartiq.Response[vartiq.App], error) {
+	if m.GetFunc == nil {
+		panic("vartiqmock: AppAPIMock.GetFunc: method is nil but AppAPI.Get was just called")
+	}
+	m.mu.Lock()
+	m.calls.Get = append(m.calls.Get, struct {
+		Ctx   context.Context
+		AppID string
+	}{Ctx: ctx, AppID: appID})
+	m.mu.Unlock()
+	return m.GetFunc(ctx, appID)
+}
+
+// GetCalls returns the arguments of every call to Get.
+func (m *AppAPIMock) GetCalls() []struct {
+	Ctx   context.Context
+	AppID string
+} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls.Get
+}
+
+// Update calls UpdateFunc.
+func (m *AppAPIMock) Update(ctx context.Context, appID string, req *vartiq.UpdateAppRequest) (*vartiq.Response[vartiq.App], error) {
+	if m.UpdateFunc == nil {
+		panic("vartiqmock: AppAPIMock.UpdateFunc: method is nil but AppAPI.Update was just called")
+	}
+	m.mu.Lock()
+	m.calls.Update = append(m.calls.Update, struct {
+		Ctx   context.Context
+		AppID string
+		Req   *vartiq.UpdateAppRequest
+	}{Ctx: ctx, AppID: appID, Req: req})
+	m.mu.Unlock()
+	return m.UpdateFunc(ctx, appID, req)
+}
+
+// UpdateCalls returns the arguments of every call to Update.
+func (m *AppAPIMock) UpdateCalls() []struct {
+	Ctx   context.Context
+	AppID string
+	Req   *vartiq.UpdateAppRequest
+} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls.Update
+}
+
+// Delete calls DeleteFunc.
+func (m *AppAPIMock) Delete(ctx context.Context, appID string) error {
+	if m.DeleteFunc == nil {
+		panic("vartiqmock: AppAPIMock.DeleteFunc: method is nil but AppAPI.Delete was just called")
+	}
+	m.mu.Lock()
+	m.calls.Delete = append(m.calls.Delete, struct {
+		Ctx   context.Context
+		AppID string
+	}{Ctx: ctx, AppID: appID})
+	m.mu.Unlock()
+	return m.DeleteFunc(ctx, appID)
+}
+
+// DeleteCalls returns the arguments of every call to Delete.
+func (m *AppAPIMock) DeleteCalls() []struct {
+	Ctx   context.Context
+	AppID string
+} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls.Delete
+}
+
+// WebhookAPIMock is a mock implementation of vartiq.WebhookAPI.
+type WebhookAPIMock struct {
+	// CreateFunc mocks the Create method.
+	CreateFunc func(ctx context.Context, req *vartiq.CreateWebhookRequest, opts ...vartiq.RequestOption) (*vartiq.Response[vartiq.Webhook], error)
+
+	// GetAllFunc mocks the GetAll method.
+	GetAllFunc func(ctx context.Context, appID string) (*vartiq.Response[[]vartiq.Webhook], error)
+
+	// GetOneFunc mocks the GetOne method.
+	GetOneFunc func(ctx context.Context, webhookID string) (*vartiq.Response[vartiq.Webhook], error)
+
+	// UpdateFunc mocks the Update method.
+	UpdateFunc func(ctx context.Context, webhookID string, req map[string]interface{}) (*vartiq.Response[vartiq.Webhook], error)
+
+	// UpdateWebhookFunc mocks the UpdateWebhook method.
+	UpdateWebhookFunc func(ctx context.Context, webhookID string, req *vartiq.UpdateWebhookRequest) (*vartiq.Response[vartiq.Webhook], error)
+
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(ctx context.Context, webhookID string) error
+
+	mu    sync.Mutex
+	calls struct {
+		Create []struct {
+			Ctx  context.Context
+			Req  *vartiq.CreateWebhookRequest
+			Opts []vartiq.RequestOption
+		}
+		GetAll []struct {
+			Ctx   context.Context
+			AppID string
+		}
+		GetOne []struct {
+			Ctx       context.Context
+			WebhookID string
+		}
+		Update []struct {
+			Ctx       context.Context
+			WebhookID string
+			Req       map[string]interface{}
+		}
+		UpdateWebhook []struct {
+			Ctx       context.Context
+			WebhookID string
+			Req       *vartiq.UpdateWebhookRequest
+		}
+		Delete []struct {
+			Ctx       context.Context
+			WebhookID string
+		}
+	}
+}
+
+var _ vartiq.WebhookAPI = (*WebhookAPIMock)(nil)
+
+// Create calls CreateFunc.
+func (m *WebhookAPIMock) Create(ctx context.Context, req *vartiq.CreateWebhookRequest, opts ...vartiq.RequestOption) (*vartiq.Response[vartiq.Webhook], error) {
+	if m.CreateFunc == nil {
+		panic("vartiqmock: WebhookAPIMock.CreateFunc: method is nil but WebhookAPI.Create was just called")
+	}
+	m.mu.Lock()
+	m.calls.Create = append(m.calls.Create, struct {
+		Ctx  context.Context
+		Req  *vartiq.CreateWebhookRequest
+		Opts []vartiq.RequestOption
+	}{Ctx: ctx, Req: req, Opts: opts})
+	m.mu.Unlock()
+	return m.CreateFunc(ctx, req, opts...)
+}
+
+// CreateCalls returns the arguments of every call to Create.
+func (m *WebhookAPIMock) CreateCalls() []struct {
+	Ctx  context.Context
+	Req  *vartiq.CreateWebhookRequest
+	Opts []vartiq.RequestOption
+} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls.Create
+}
+
+// GetAll calls GetAllFunc.
+func (m *WebhookAPIMock) GetAll(ctx context.Context, appID string) (*vartiq.Response[[]vartiq.Webhook], error) {
+	if m.GetAllFunc == nil {
+		panic("vartiqmock: WebhookAPIMock.GetAllFunc: method is nil but WebhookAPI.GetAll was just called")
+	}
+	m.mu.Lock()
+	m.calls.GetAll = append(m.calls.GetAll, struct {
+		Ctx   context.Context
+		AppID string
+	}{Ctx: ctx, AppID: appID})
+	m.mu.Unlock()
+	return m.GetAllFunc(ctx, appID)
+}
+
+// GetAllCalls returns the arguments of every call to GetAll.
+func (m *WebhookAPIMock) GetAllCalls() []struct {
+	Ctx   context.Context
+	AppID string
+} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls.GetAll
+}
+
+// GetOne calls GetOneFunc.
+func (m *WebhookAPIMock) GetOne(ctx context.Context, webhookID string) (*vartiq.Response[vartiq.Webhook], error) {
+	if m.GetOneFunc == nil {
+		panic("vartiqmock: WebhookAPIMock.GetOneFunc: method is nil but WebhookAPI.GetOne was just called")
+	}
+	m.mu.Lock()
+	m.calls.GetOne = append(m.calls.GetOne, struct {
+		Ctx       context.Context
+		WebhookID string
+	}{Ctx: ctx, WebhookID: webhookID})
+	m.mu.Unlock()
+	return m.GetOneFunc(ctx, webhookID)
+}
+
+// GetOneCalls returns the arguments of every call to GetOne.
+func (m *WebhookAPIMock) GetOneCalls() []struct {
+	Ctx       context.Context
+	WebhookID string
+} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls.GetOne
+}
+
+// Update calls UpdateFunc.
+func (m *WebhookAPIMock) Update(ctx context.Context, webhookID string, req map[string]interface{}) (*vartiq.Response[vartiq.Webhook], error) {
+	if m.UpdateFunc == nil {
+		panic("vartiqmock: WebhookAPIMock.UpdateFunc: method is nil but WebhookAPI.Update was just called")
+	}
+	m.mu.Lock()
+	m.calls.Update = append(m.calls.Update, struct {
+		Ctx       context.Context
+		WebhookID string
+		Req       map[string]interface{}
+	}{Ctx: ctx, WebhookID: webhookID, Req: req})
+	m.mu.Unlock()
+	return m.UpdateFunc(ctx, webhookID, req)
+}
+
+// UpdateCalls returns the arguments of every call to Update.
+func (m *WebhookAPIMock) UpdateCalls() []struct {
+	Ctx       context.Context
+	WebhookID string
+	Req       map[string]interface{}
+} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls.Update
+}
+
+// UpdateWebhook calls UpdateWebhookFunc.
+func (m *WebhookAPIMock) UpdateWebhook(ctx context.Context, webhookID string, req *vartiq.UpdateWebhookRequest) (*vartiq.Response[vartiq.Webhook], error) {
+	if m.UpdateWebhookFunc == nil {
+		panic("vartiqmock: WebhookAPIMock.UpdateWebhookFunc: method is nil but WebhookAPI.UpdateWebhook was just called")
+	}
+	m.mu.Lock()
+	m.calls.UpdateWebhook = append(m.calls.UpdateWebhook, struct {
+		Ctx       context.Context
+		WebhookID string
+		Req       *vartiq.UpdateWebhookRequest
+	}{Ctx: ctx, WebhookID: webhookID, Req: req})
+	m.mu.Unlock()
+	return m.UpdateWebhookFunc(ctx, webhookID, req)
+}
+
+// UpdateWebhookCalls returns the arguments of every call to UpdateWebhook.
+func (m *WebhookAPIMock) UpdateWebhookCalls() []struct {
+	Ctx       context.Context
+	WebhookID string
+	Req       *vartiq.UpdateWebhookRequest
+} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls.UpdateWebhook
+}
+
+// Delete calls DeleteFunc.
+func (m *WebhookAPIMock) Delete(ctx context.Context, webhookID string) error {
+	if m.DeleteFunc == nil {
+		panic("vartiqmock: WebhookAPIMock.DeleteFunc: method is nil but WebhookAPI.Delete was just called")
+	}
+	m.mu.Lock()
+	m.calls.Delete = append(m.calls.Delete, struct {
+		Ctx       context.Context
+		WebhookID string
+	}{Ctx: ctx, WebhookID: webhookID})
+	m.mu.Unlock()
+	return m.DeleteFunc(ctx, webhookID)
+}
+
+// DeleteCalls returns the arguments of every call to Delete.
+func (m *WebhookAPIMock) DeleteCalls() []struct {
+	Ctx       context.Context
+	WebhookID string
+} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls.Delete
+}
+
+// WebhookMessageAPIMock is a mock implementation of vartiq.WebhookMessageAPI.
+type WebhookMessageAPIMock struct {
+	// CreateFunc mocks the Create method.
+	CreateFunc func(ctx context.Context, appID string, payload interface{}, opts ...vartiq.RequestOption) (*vartiq.Response[vartiq.WebhookMessage], error)
+
+	// CreateBatchFunc mocks the CreateBatch method.
+	CreateBatchFunc func(ctx context.Context, appID string, payloads []interface{}, opts ...vartiq.RequestOption) ([]vartiq.BatchResult, error)
+
+	// ListFunc mocks the List method.
+	ListFunc func(ctx context.Context, appID string, filter *vartiq.WebhookMessageFilter) (*vartiq.Response[[]vartiq.WebhookMessage], error)
+
+	// GetFunc mocks the Get method.
+	GetFunc func(ctx context.Context, messageID string) (*vartiq.Response[vartiq.WebhookMessage], error)
+
+	// ListAttemptsFunc mocks the ListAttempts method.
+	ListAttemptsFunc func(ctx context.Context, messageID string) (*vartiq.Response[[]vartiq.DeliveryAttempt], error)
+
+	// RetryFunc mocks the Retry method.
+	RetryFunc func(ctx context.Context, messageID string, opts *vartiq.RetryMessageOptions, reqOpts ...vartiq.RequestOption) (*vartiq.Response[vartiq.RetryResult], error)
+
+	// ReplayFunc mocks the Replay method.
+	ReplayFunc func(ctx context.Context, appID string, since time.Time, until time.Time, filter *vartiq.ReplayFilter) (*vartiq.ReplayResult, error)
+
+	// WaitForDeliveryFunc mocks the WaitForDelivery method.
+	WaitForDeliveryFunc func(ctx context.Context, messageID string, opts *vartiq.WaitOptions) (*vartiq.DeliveryStatus, error)
+
+	mu    sync.Mutex
+	calls struct {
+		Create []struct {
+			Ctx     context.Context
+			AppID   string
+			Payload interface{}
+			Opts    []vartiq.RequestOption
+		}
+		CreateBatch []struct {
+			Ctx      context.Context
+			AppID    string
+			Payloads []interface{}
+			Opts     []vartiq.RequestOption
+		}
+		List []struct {
+			Ctx    context.Context
+			AppID  string
+			Filter *vartiq.WebhookMessageFilter
+		}
+		Get []struct {
+			Ctx       context.Context
+			MessageID string
+		}
+		ListAttempts []struct {
+			Ctx       context.Context
+			MessageID string
+		}
+		Retry []struct {
+			Ctx       context.Context
+			MessageID string
+			Opts      *vartiq.RetryMessageOptions
+			ReqOpts   []vartiq.RequestOption
+		}
+		Replay []struct {
+			Ctx    context.Context
+			AppID  string
+			Since  time.Time
+			Until  time.Time
+			Filter *vartiq.ReplayFilter
+		}
+		WaitForDelivery []struct {
+			Ctx       context.Context
+			MessageID string
+			Opts      *vartiq.WaitOptions
+		}
+	}
+}
+
+var _ vartiq.WebhookMessageAPI = (*WebhookMessageAPIMock)(nil)
+
+// Create calls CreateFunc.
+func (m *WebhookMessageAPIMock) Create(ctx context.Context, appID string, payload interface{}, opts ...vartiq.RequestOption) (*vartiq.Response[vartiq.WebhookMessage], error) {
+	if m.CreateFunc == nil {
+		panic("vartiqmock: WebhookMessageAPIMock.CreateFunc: method is nil but WebhookMessageAPI.Create was just called")
+	}
+	m.mu.Lock()
+	m.calls.Create = append(m.calls.Create, struct {
+		Ctx     context.Context
+		AppID   string
+		Payload interface{}
+		Opts    []vartiq.RequestOption
+	}{Ctx: ctx, AppID: appID, Payload: payload, Opts: opts})
+	m.mu.Unlock()
+	return m.CreateFunc(ctx, appID, payload, opts...)
+}
+
+// CreateCalls returns the arguments of every call to Create.
+func (m *WebhookMessageAPIMock) CreateCalls() []struct {
+	Ctx     context.Context
+	AppID   string
+	Payload interface{}
+	Opts    []vartiq.RequestOption
+} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls.Create
+}
+
+// CreateBatch calls CreateBatchFunc.
+func (m *WebhookMessageAPIMock) CreateBatch(ctx context.Context, appID string, payloads []interface{}, opts ...vartiq.RequestOption) ([]vartiq.BatchResult, error) {
+	if m.CreateBatchFunc == nil {
+		panic("vartiqmock: WebhookMessageAPIMock.CreateBatchFunc: method is nil but WebhookMessageAPI.CreateBatch was just called")
+	}
+	m.mu.Lock()
+	m.calls.CreateBatch = append(m.calls.CreateBatch, struct {
+		Ctx      context.Context
+		AppID    string
+		Payloads []interface{}
+		Opts     []vartiq.RequestOption
+	}{Ctx: ctx, AppID: appID, Payloads: payloads, Opts: opts})
+	m.mu.Unlock()
+	return m.CreateBatchFunc(ctx, appID, payloads, opts...)
+}
+
+// CreateBatchCalls returns the arguments of every call to CreateBatch.
+func (m *WebhookMessageAPIMock) CreateBatchCalls() []struct {
+	Ctx      context.Context
+	AppID    string
+	Payloads []interface{}
+	Opts     []vartiq.RequestOption
+} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls.CreateBatch
+}
+
+// List calls ListFunc.
+func (m *WebhookMessageAPIMock) List(ctx context.Context, appID string, filter *vartiq.WebhookMessageFilter) (*vartiq.Response[[]vartiq.WebhookMessage], error) {
+	if m.ListFunc == nil {
+		panic("vartiqmock: WebhookMessageAPIMock.ListFunc: method is nil but WebhookMessageAPI.List was just called")
+	}
+	m.mu.Lock()
+	m.calls.List = append(m.calls.List, struct {
+		Ctx    context.Context
+		AppID  string
+		Filter *vartiq.WebhookMessageFilter
+	}{Ctx: ctx, AppID: appID, Filter: filter})
+	m.mu.Unlock()
+	return m.ListFunc(ctx, appID, filter)
+}
+
+// ListCalls returns the arguments of every call to List.
+func (m *WebhookMessageAPIMock) ListCalls() []struct {
+	Ctx    context.Context
+	AppID  string
+	Filter *vartiq.WebhookMessageFilter
+} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls.List
+}
+
+// Get calls GetFunc.
+func (m *WebhookMessageAPIMock) Get(ctx context.Context, messageID string) (*vartiq.Response[vartiq.WebhookMessage], error) {
+	if m.GetFunc == nil {
+		panic("vartiqmock: WebhookMessageAPIMock.GetFunc: method is nil but WebhookMessageAPI.Get was just called")
+	}
+	m.mu.Lock()
+	m.calls.Get = append(m.calls.Get, struct {
+		Ctx       context.Context
+		MessageID string
+	}{Ctx: ctx, MessageID: messageID})
+	m.mu.Unlock()
+	return m.GetFunc(ctx, messageID)
+}
+
+// GetCalls returns the arguments of every call to Get.
+func (m *WebhookMessageAPIMock) GetCalls() []struct {
+	Ctx       context.Context
+	MessageID string
+} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls.Get
+}
+
+// ListAttempts calls ListAttemptsFunc.
+func (m *WebhookMessageAPIMock) ListAttempts(ctx context.Context, messageID string) (*vartiq.Response[[]vartiq.DeliveryAttempt], error) {
+	if m.ListAttemptsFunc == nil {
+		panic("vartiqmock: WebhookMessageAPIMock.ListAttemptsFunc: method is nil but WebhookMessageAPI.ListAttempts was just called")
+	}
+	m.mu.Lock()
+	m.calls.ListAttempts = append(m.calls.ListAttempts, struct {
+		Ctx       context.Context
+		MessageID string
+	}{Ctx: ctx, MessageID: messageID})
+	m.mu.Unlock()
+	return m.ListAttemptsFunc(ctx, messageID)
+}
+
+// ListAttemptsCalls returns the arguments of every call to ListAttempts.
+func (m *WebhookMessageAPIMock) ListAttemptsCalls() []struct {
+	Ctx       context.Context
+	MessageID string
+} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls.ListAttempts
+}
+
+// Retry calls RetryFunc.
+func (m *WebhookMessageAPIMock) Retry(ctx context.Context, messageID string, opts *vartiq.RetryMessageOptions, reqOpts ...vartiq.RequestOption) (*vartiq.Response[vartiq.RetryResult], error) {
+	if m.RetryFunc == nil {
+		panic("vartiqmock: WebhookMessageAPIMock.RetryFunc: method is nil but WebhookMessageAPI.Retry was just called")
+	}
+	m.mu.Lock()
+	m.calls.Retry = append(m.calls.Retry, struct {
+		Ctx       context.Context
+		MessageID string
+		Opts      *vartiq.RetryMessageOptions
+		ReqOpts   []vartiq.RequestOption
+	}{Ctx: ctx, MessageID: messageID, Opts: opts, ReqOpts: reqOpts})
+	m.mu.Unlock()
+	return m.RetryFunc(ctx, messageID, opts, reqOpts...)
+}
+
+// RetryCalls returns the arguments of every call to Retry.
+func (m *WebhookMessageAPIMock) RetryCalls() []struct {
+	Ctx       context.Context
+	MessageID string
+	Opts      *vartiq.RetryMessageOptions
+	ReqOpts   []vartiq.RequestOption
+} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls.Retry
+}
+
+// Replay calls ReplayFunc.
+func (m *WebhookMessageAPIMock) Replay(ctx context.Context, appID string, since time.Time, until time.Time, filter *vartiq.ReplayFilter) (*vartiq.ReplayResult, error) {
+	if m.ReplayFunc == nil {
+		panic("vartiqmock: WebhookMessageAPIMock.ReplayFunc: method is nil but WebhookMessageAPI.Replay was just called")
+	}
+	m.mu.Lock()
+	m.calls.Replay = append(m.calls.Replay, struct {
+		Ctx    context.Context
+		AppID  string
+		Since  time.Time
+		Until  time.Time
+		Filter *vartiq.ReplayFilter
+	}{Ctx: ctx, AppID: appID, Since: since, Until: until, Filter: filter})
+	m.mu.Unlock()
+	return m.ReplayFunc(ctx, appID, since, until, filter)
+}
+
+// ReplayCalls returns the arguments of every call to Replay.
+func (m *WebhookMessageAPIMock) ReplayCalls() []struct {
+	Ctx    context.Context
+	AppID  string
+	Since  time.Time
+	Until  time.Time
+	Filter *vartiq.ReplayFilter
+} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls.Replay
+}
+
+// WaitForDelivery calls WaitForDeliveryFunc.
+func (m *WebhookMessageAPIMock) WaitForDelivery(ctx context.Context, messageID string, opts *vartiq.WaitOptions) (*vartiq.DeliveryStatus, error) {
+	if m.WaitForDeliveryFunc == nil {
+		panic("vartiqmock: WebhookMessageAPIMock.WaitForDeliveryFunc: method is nil but WebhookMessageAPI.WaitForDelivery was just called")
+	}
+	m.mu.Lock()
+	m.calls.WaitForDelivery = append(m.calls.WaitForDelivery, struct {
+		Ctx       context.Context
+		MessageID string
+		Opts      *vartiq.WaitOptions
+	}{Ctx: ctx, MessageID: messageID, Opts: opts})
+	m.mu.Unlock()
+	return m.WaitForDeliveryFunc(ctx, messageID, opts)
+}
+
+// WaitForDeliveryCalls returns the arguments of every call to WaitForDelivery.
+func (m *WebhookMessageAPIMock) WaitForDeliveryCalls() []struct {
+	Ctx       context.Context
+	MessageID string
+	Opts      *vartiq.WaitOptions
+} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls.WaitForDelivery
+}
+
+// EventTypeAPIMock is a mock implementation of vartiq.EventTypeAPI.
+type EventTypeAPIMock struct {
+	// CreateFunc mocks the Create method.
+	CreateFunc func(ctx context.Context, req *vartiq.CreateEventTypeRequest, opts ...vartiq.RequestOption) (*vartiq.Response[vartiq.EventType], error)
+
+	// ListFunc mocks the List method.
+	ListFunc func(ctx context.Context, appID string) (*vartiq.Response[[]vartiq.EventType], error)
+
+	// DeprecateFunc mocks the Deprecate method.
+	DeprecateFunc func(ctx context.Context, eventTypeID string) (*vartiq.Response[vartiq.EventType], error)
+
+	mu    sync.Mutex
+	calls struct {
+		Create []struct {
+			Ctx  context.Context
+			Req  *vartiq.CreateEventTypeRequest
+			Opts []vartiq.RequestOption
+		}
+		List []struct {
+			Ctx   context.Context
+			AppID string
+		}
+		Deprecate []struct {
+			Ctx         context.Context
+			EventTypeID string
+		}
+	}
+}
+
+var _ vartiq.EventTypeAPI = (*EventTypeAPIMock)(nil)
+
+// Create calls CreateFunc.
+func (m *EventTypeAPIMock) Create(ctx context.Context, req *vartiq.CreateEventTypeRequest, opts ...vartiq.RequestOption) (*vartiq.Response[vartiq.EventType], error) {
+	if m.CreateFunc == nil {
+		panic("vartiqmock: EventTypeAPIMock.CreateFunc: method is nil but EventTypeAPI.Create was just called")
+	}
+	m.mu.Lock()
+	m.calls.Create = append(m.calls.Create, struct {
+		Ctx  context.Context
+		Req  *vartiq.CreateEventTypeRequest
+		Opts []vartiq.RequestOption
+	}{Ctx: ctx, Req: req, Opts: opts})
+	m.mu.Unlock()
+	return m.CreateFunc(ctx, req, opts...)
+}
+
+// CreateCalls returns the arguments of every call to Create.
+func (m *EventTypeAPIMock) CreateCalls() []struct {
+	Ctx  context.Context
+	Req  *vartiq.CreateEventTypeRequest
+	Opts []vartiq.RequestOption
+} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls.Create
+}
+
+// List calls ListFunc.
+func (m *EventTypeAPIMock) List(ctx context.Context, appID string) (*vartiq.Response[[]vartiq.EventType], error) {
+	if m.ListFunc == nil {
+		panic("vartiqmock: EventTypeAPIMock.ListFunc: method is nil but EventTypeAPI.List was just called")
+	}
+	m.mu.Lock()
+	m.calls.List = append(m.calls.List, struct {
+		Ctx   context.Context
+		AppID string
+	}{Ctx: ctx, AppID: appID})
+	m.mu.Unlock()
+	return m.ListFunc(ctx, appID)
+}
+
+// ListCalls returns the arguments of every call to List.
+func (m *EventTypeAPIMock) ListCalls() []struct {
+	Ctx   context.Context
+	AppID string
+} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls.List
+}
+
+// Deprecate calls DeprecateFunc.
+func (m *EventTypeAPIMock) Deprecate(ctx context.Context, eventTypeID string) (*vartiq.Response[vartiq.EventType], error) {
+	if m.DeprecateFunc == nil {
+		panic("vartiqmock: EventTypeAPIMock.DeprecateFunc: method is nil but EventTypeAPI.Deprecate was just called")
+	}
+	m.mu.Lock()
+	m.calls.Deprecate = append(m.calls.Deprecate, struct {
+		Ctx         context.Context
+		EventTypeID string
+	}{Ctx: ctx, EventTypeID: eventTypeID})
+	m.mu.Unlock()
+	return m.DeprecateFunc(ctx, eventTypeID)
+}
+
+// DeprecateCalls returns the arguments of every call to Deprecate.
+func (m *EventTypeAPIMock) DeprecateCalls() []struct {
+	Ctx         context.Context
+	EventTypeID string
+} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls.Deprecate
+}
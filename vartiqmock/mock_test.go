@@ -0,0 +1,57 @@
+package vartiqmock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+)
+
+// sendWelcome stands in for application code that depends on vartiq.API.
+func sendWelcome(ctx context.Context, api vartiq.API, appID, user string) (string, error) {
+	resp, err := api.WebhookMessages().Create(ctx, appID, map[string]interface{}{"user": user})
+	if err != nil {
+		return "", err
+	}
+	return resp.Data.ID, nil
+}
+
+func TestClient_RecordsCalls(t *testing.T) {
+	messages := &WebhookMessageAPIMock{
+		CreateFunc: func(ctx context.Context, appID string, payload interface{}, opts ...vartiq.RequestOption) (*vartiq.Response[vartiq.WebhookMessage], error) {
+			return &vartiq.Response[vartiq.WebhookMessage]{Data: vartiq.WebhookMessage{ID: "msg-1", AppID: appID}}, nil
+		},
+	}
+	client := &Client{WebhookMessageMock: messages}
+
+	id, err := sendWelcome(context.Background(), client, "app-1", "ada")
+	require.NoError(t, err)
+	assert.Equal(t, "msg-1", id)
+
+	calls := messages.CreateCalls()
+	require.Len(t, calls, 1)
+	assert.Equal(t, "app-1", calls[0].AppID)
+	assert.Equal(t, map[string]interface{}{"user": "ada"}, calls[0].Payload)
+}
+
+func TestClient_ReturnsErrors(t *testing.T) {
+	projects := &ProjectAPIMock{
+		DeleteFunc: func(ctx context.Context, projectID string) error {
+			return &vartiq.APIError{Code: 404, Message: "not found"}
+		},
+	}
+	client := &Client{ProjectMock: projects}
+
+	err := client.Projects().Delete(context.Background(), "p1")
+	assert.ErrorIs(t, err, vartiq.ErrNotFound)
+	assert.Len(t, projects.DeleteCalls(), 1)
+}
+
+func TestClient_UnsetFuncPanics(t *testing.T) {
+	client := &Client{}
+	assert.PanicsWithValue(t,
+		"vartiqmock: AppAPIMock.GetFunc: method is nil but AppAPI.Get was just called",
+		func() { _, _ = client.Apps().Get(context.Background(), "a1") })
+}
@@ -0,0 +1,519 @@
+package vartiqtest
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+)
+
+// SignatureHeader is the header carrying a message's signature in the
+// API's response, matching what vartiq.WebhookMessageService.Create reads.
+const SignatureHeader = "x-Vartiq-signature"
+
+// message is a stored webhook message in the API's wire format. The API
+// creates one message per webhook registered on the app.
+type message struct {
+	ID          string          `json:"id"`
+	AppID       string          `json:"app"`
+	WebhookID   string          `json:"webhook,omitempty"`
+	EventType   string          `json:"eventType,omitempty"`
+	Payload     string          `json:"payload"`
+	Headers     []vartiq.Header `json:"headers"`
+	IsDelivered bool            `json:"isDelivered"`
+	CreatedAt   string          `json:"createdAt"`
+	UpdatedAt   string          `json:"updatedAt"`
+
+	created time.Time
+}
+
+// responseExcerptLimit caps the response body kept for each delivery.
+const responseExcerptLimit = 1024
+
+// Delivery records one attempt to deliver a message to a webhook URL.
+type Delivery struct {
+	ID        string
+	MessageID string
+	WebhookID string
+	URL       string
+	// Attempt numbers the deliveries of the same message to the same
+	// webhook, from 1.
+	Attempt    int
+	Body       []byte
+	Header     http.Header
+	StatusCode int
+	// Response holds the first bytes of the endpoint's response body.
+	Response []byte
+	Err      error
+	Duration time.Duration
+	At       time.Time
+}
+
+// attempt is a Delivery in the API's wire format.
+type attempt struct {
+	ID           string `json:"id"`
+	MessageID    string `json:"message"`
+	WebhookID    string `json:"webhook"`
+	URL          string `json:"url"`
+	Attempt      int    `json:"attempt"`
+	StatusCode   int    `json:"statusCode"`
+	ResponseBody string `json:"responseBody"`
+	LatencyMs    int64  `json:"latencyMs"`
+	Error        string `json:"error,omitempty"`
+	CreatedAt    string `json:"createdAt"`
+}
+
+// Sign returns the hex HMAC-SHA256 of body, as verified by vartiq.Client.Verify.
+func Sign(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// signatureHeaders returns the headers signing body for a message, with the
+// signature under signatureHeader. Unless deliveries are signed over the
+// body only, the signature covers the message ID and signing time, which are
+// sent alongside it.
+func (s *Server) signatureHeaders(signatureHeader, messageID string, at time.Time, body []byte, secret string) ([]vartiq.Header, error) {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	switch s.signatures {
+	case signTimestamped:
+		return []vartiq.Header{
+			{Key: signatureHeader, Value: vartiq.SignWithTimestamp(messageID, at, body, secret)},
+			{Key: vartiq.MessageIDHeader, Value: messageID},
+			{Key: vartiq.TimestampHeader, Value: timestamp},
+		}, nil
+	case signStandard:
+		signature, err := vartiq.SignStandard(messageID, at, body, secret)
+		if err != nil {
+			return nil, err
+		}
+		return []vartiq.Header{
+			{Key: signatureHeader, Value: signature},
+			{Key: vartiq.StandardIDHeader, Value: messageID},
+			{Key: vartiq.StandardTimestampHeader, Value: timestamp},
+		}, nil
+	}
+	return []vartiq.Header{{Key: signatureHeader, Value: Sign(body, secret)}}, nil
+}
+
+func (s *Server) handleWebhookMessages(w http.ResponseWriter, r *http.Request, id, sub string) {
+	switch {
+	case sub == "attempts" && r.Method == http.MethodGet:
+		s.listAttempts(w, id)
+	case sub == "retry" && r.Method == http.MethodPost:
+		s.retryMessage(w, r, id)
+	case sub != "":
+		writeError(w, http.StatusNotFound, "Route not found")
+	case id == "" && r.Method == http.MethodPost:
+		s.createMessages(w, r)
+	case id == "batch" && r.Method == http.MethodPost:
+		s.createBatch(w, r)
+	case id == "" && r.Method == http.MethodGet:
+		s.listMessages(w, r)
+	case id != "" && r.Method == http.MethodGet:
+		s.getMessage(w, id)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+func (s *Server) createMessages(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		AppID     string          `json:"appId"`
+		EventType string          `json:"eventType"`
+		Payload   json.RawMessage `json:"payload"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+	if fields := required("appId", req.AppID, "payload", string(req.Payload)); fields != nil {
+		writeValidationError(w, http.StatusBadRequest, "Validation failed", fields)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.apps.get(req.AppID); !ok {
+		writeError(w, http.StatusNotFound, "App not found")
+		return
+	}
+	if fields := s.checkEventType(req.AppID, req.EventType); fields != nil {
+		writeValidationError(w, http.StatusBadRequest, "Validation failed", fields)
+		return
+	}
+
+	writeData(w, http.StatusCreated, "Webhook message created successfully", map[string]interface{}{
+		"webhookMessages": s.fanOut(req.AppID, req.EventType, req.Payload),
+	})
+}
+
+// batchItem is one entry of a batch response.
+type batchItem struct {
+	WebhookMessages []message        `json:"webhookMessages,omitempty"`
+	Error           *vartiq.APIError `json:"error,omitempty"`
+}
+
+// createBatch serves POST /webhook-messages/batch. Invalid entries get an
+// error result; the others are created as with a single message.
+func (s *Server) createBatch(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		AppID    string `json:"appId"`
+		Messages []struct {
+			EventType string          `json:"eventType"`
+			Payload   json.RawMessage `json:"payload"`
+		} `json:"messages"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+	if fields := required("appId", req.AppID); fields != nil {
+		writeValidationError(w, http.StatusBadRequest, "Validation failed", fields)
+		return
+	}
+	if len(req.Messages) == 0 || len(req.Messages) > vartiq.MaxBatchSize {
+		writeValidationError(w, http.StatusBadRequest, "Validation failed", []vartiq.FieldError{
+			{Field: "messages", Message: fmt.Sprintf("must contain between 1 and %d messages", vartiq.MaxBatchSize)},
+		})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.apps.get(req.AppID); !ok {
+		writeError(w, http.StatusNotFound, "App not found")
+		return
+	}
+
+	results := make([]batchItem, len(req.Messages))
+	for i, m := range req.Messages {
+		if len(m.Payload) == 0 || string(m.Payload) == "null" {
+			results[i].Error = &vartiq.APIError{
+				Code:    http.StatusBadRequest,
+				Message: "Validation failed",
+				Fields:  []vartiq.FieldError{{Field: "payload", Message: "is required"}},
+			}
+			continue
+		}
+		if fields := s.checkEventType(req.AppID, m.EventType); fields != nil {
+			results[i].Error = &vartiq.APIError{Code: http.StatusBadRequest, Message: "Validation failed", Fields: fields}
+			continue
+		}
+		results[i].WebhookMessages = s.fanOut(req.AppID, m.EventType, m.Payload)
+	}
+
+	writeData(w, http.StatusCreated, "Webhook messages created successfully", map[string]interface{}{
+		"results": results,
+	})
+}
+
+// checkEventType returns a field error unless eventType is empty or
+// registered on the app. Callers hold s.mu.
+func (s *Server) checkEventType(appID, eventType string) []vartiq.FieldError {
+	if _, ok := s.eventType(appID, eventType); eventType != "" && !ok {
+		return []vartiq.FieldError{{Field: "eventType", Message: "unknown event type " + eventType}}
+	}
+	return nil
+}
+
+// fanOut stores one message per webhook of the app subscribed to eventType,
+// or a single untargeted message when there is none, and starts delivering
+// them. Webhooks without subscriptions receive every message. Callers hold
+// s.mu.
+func (s *Server) fanOut(appID, eventType string, payload json.RawMessage) []message {
+	var compact bytes.Buffer
+	_ = json.Compact(&compact, payload)
+	body := compact.Bytes()
+
+	targets := s.webhooks.all(func(wh *vartiq.Webhook) bool {
+		return wh.AppID == appID && (len(wh.EventTypes) == 0 || contains(wh.EventTypes, eventType))
+	})
+	at := s.now()
+	now := s.timestamp()
+	created := []message{}
+	newMessage := func(webhookID string, secret *string) *message {
+		m := &message{
+			ID: s.newID(), AppID: appID, WebhookID: webhookID, EventType: eventType, Payload: string(body),
+			Headers: []vartiq.Header{}, CreatedAt: now, UpdatedAt: now, created: at,
+		}
+		if secret != nil {
+			if headers, err := s.signatureHeaders(SignatureHeader, m.ID, at, body, *secret); err == nil {
+				m.Headers = headers
+			}
+		}
+		s.messages.put(m.ID, m)
+		created = append(created, *m)
+		return m
+	}
+	if len(targets) == 0 {
+		newMessage("", nil)
+	}
+	for _, wh := range targets {
+		var secret *string
+		if wh.AuthMethod != nil && wh.AuthMethod.Method == vartiq.AuthMethodHMAC {
+			secret = &wh.AuthMethod.HMACSecret
+		}
+		m := newMessage(wh.ID, secret)
+		s.startDelivery(m.ID, wh, body)
+	}
+	return created
+}
+
+// listMessages serves GET /webhook-messages, newest first, filtered by the
+// isDelivered, since, until, eventType, limit and offset query parameters.
+func (s *Server) listMessages(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	appID := q.Get("appId")
+	if fields := required("appId", appID); fields != nil {
+		writeValidationError(w, http.StatusBadRequest, "Validation failed", fields)
+		return
+	}
+
+	var invalid []vartiq.FieldError
+	var delivered *bool
+	if v := q.Get("isDelivered"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			invalid = append(invalid, vartiq.FieldError{Field: "isDelivered", Message: "must be a boolean"})
+		}
+		delivered = &b
+	}
+	parseTime := func(name string) time.Time {
+		v := q.Get(name)
+		if v == "" {
+			return time.Time{}
+		}
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			invalid = append(invalid, vartiq.FieldError{Field: name, Message: "must be an RFC 3339 timestamp"})
+		}
+		return t
+	}
+	since, until := parseTime("since"), parseTime("until")
+	parseInt := func(name string) int {
+		v := q.Get(name)
+		if v == "" {
+			return 0
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			invalid = append(invalid, vartiq.FieldError{Field: name, Message: "must be a non-negative integer"})
+		}
+		return n
+	}
+	limit, offset := parseInt("limit"), parseInt("offset")
+	if invalid != nil {
+		writeValidationError(w, http.StatusBadRequest, "Validation failed", invalid)
+		return
+	}
+	eventType := q.Get("eventType")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.apps.get(appID); !ok {
+		writeError(w, http.StatusNotFound, "App not found")
+		return
+	}
+	matched := s.messages.all(func(m *message) bool {
+		return m.AppID == appID &&
+			(delivered == nil || m.IsDelivered == *delivered) &&
+			(since.IsZero() || !m.created.Before(since)) &&
+			(until.IsZero() || m.created.Before(until)) &&
+			(eventType == "" || m.EventType == eventType)
+	})
+
+	// Stored oldest first; the API lists newest first.
+	for i, j := 0, len(matched)-1; i < j; i, j = i+1, j-1 {
+		matched[i], matched[j] = matched[j], matched[i]
+	}
+	if offset > len(matched) {
+		offset = len(matched)
+	}
+	matched = matched[offset:]
+	if limit > 0 && limit < len(matched) {
+		matched = matched[:limit]
+	}
+
+	writeData(w, http.StatusOK, "Webhook messages retrieved successfully", map[string]interface{}{
+		"webhookMessages": matched,
+	})
+}
+
+func (s *Server) getMessage(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.messages.get(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Webhook message not found")
+		return
+	}
+	writeData(w, http.StatusOK, "Webhook message retrieved successfully", m)
+}
+
+func (s *Server) listAttempts(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.messages.get(id); !ok {
+		writeError(w, http.StatusNotFound, "Webhook message not found")
+		return
+	}
+	attempts := []attempt{}
+	for _, d := range s.deliveries {
+		if d.MessageID != id {
+			continue
+		}
+		a := attempt{
+			ID: d.ID, MessageID: d.MessageID, WebhookID: d.WebhookID, URL: d.URL,
+			Attempt: d.Attempt, StatusCode: d.StatusCode, ResponseBody: string(d.Response),
+			LatencyMs: d.Duration.Milliseconds(), CreatedAt: formatTime(d.At),
+		}
+		if d.Err != nil {
+			a.Error = d.Err.Error()
+		}
+		attempts = append(attempts, a)
+	}
+	writeData(w, http.StatusOK, "Delivery attempts retrieved successfully", map[string]interface{}{
+		"attempts": attempts,
+	})
+}
+
+// retryMessage redelivers a message to its webhook. Each stored message
+// targets a single webhook, so webhookIds must be empty or include it.
+func (s *Server) retryMessage(w http.ResponseWriter, r *http.Request, id string) {
+	var req struct {
+		WebhookIDs []string `json:"webhookIds"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.messages.get(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Webhook message not found")
+		return
+	}
+	queued := []string{}
+	if m.WebhookID != "" && (len(req.WebhookIDs) == 0 || contains(req.WebhookIDs, m.WebhookID)) {
+		queued = append(queued, m.WebhookID)
+	}
+	if len(req.WebhookIDs) > len(queued) {
+		writeValidationError(w, http.StatusBadRequest, "Validation failed", []vartiq.FieldError{
+			{Field: "webhookIds", Message: "must only contain webhooks the message was sent to"},
+		})
+		return
+	}
+	for _, webhookID := range queued {
+		wh, ok := s.webhooks.get(webhookID)
+		if !ok {
+			writeError(w, http.StatusNotFound, "Webhook not found")
+			return
+		}
+		s.startDelivery(m.ID, *wh, []byte(m.Payload))
+	}
+
+	writeData(w, http.StatusOK, "Webhook message queued for redelivery", map[string]interface{}{
+		"id":         m.ID,
+		"webhookIds": queued,
+	})
+}
+
+func contains(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// startDelivery sends body to wh in the background. Callers hold s.mu.
+func (s *Server) startDelivery(messageID string, wh vartiq.Webhook, body []byte) {
+	if s.closed {
+		return
+	}
+	s.inFlight++
+	go func() {
+		d := s.deliver(messageID, wh, body)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		defer func() {
+			if s.inFlight--; s.inFlight == 0 {
+				s.idle.Broadcast()
+			}
+		}()
+		d.ID = s.newID()
+		d.Attempt = 1
+		for _, prev := range s.deliveries {
+			if prev.MessageID == d.MessageID && prev.WebhookID == d.WebhookID {
+				d.Attempt++
+			}
+		}
+		s.deliveries = append(s.deliveries, d)
+		if m, ok := s.messages.get(messageID); ok && d.Err == nil && d.StatusCode >= 200 && d.StatusCode < 300 {
+			m.IsDelivered = true
+			m.UpdatedAt = s.timestamp()
+		}
+	}()
+}
+
+// deliver performs a single HTTP delivery of body to wh, authenticated
+// according to the webhook's auth method.
+func (s *Server) deliver(messageID string, wh vartiq.Webhook, body []byte) Delivery {
+	d := Delivery{MessageID: messageID, WebhookID: wh.ID, URL: wh.URL, Body: body, At: s.now()}
+
+	req, err := http.NewRequest(http.MethodPost, wh.URL, bytes.NewReader(body))
+	if err != nil {
+		d.Err = err
+		return d
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for _, h := range wh.CustomHeaders {
+		req.Header.Set(h.Key, h.Value)
+	}
+	if auth := wh.AuthMethod; auth != nil {
+		switch auth.Method {
+		case vartiq.AuthMethodBasic:
+			req.SetBasicAuth(auth.UserName, auth.Password)
+		case vartiq.AuthMethodAPIKey:
+			req.Header.Set(auth.APIKeyHeader, auth.APIKey)
+		case vartiq.AuthMethodHMAC:
+			header := auth.HMACHeader
+			if s.signatures == signStandard {
+				header = vartiq.StandardSignatureHeader
+			}
+			headers, err := s.signatureHeaders(header, messageID, d.At, body, auth.HMACSecret)
+			if err != nil {
+				d.Err = err
+				return d
+			}
+			for _, h := range headers {
+				req.Header.Set(h.Key, h.Value)
+			}
+		}
+	}
+	d.Header = req.Header
+
+	start := time.Now()
+	resp, err := s.deliveryClient.Do(req)
+	d.Duration = time.Since(start)
+	if err != nil {
+		d.Err = err
+		return d
+	}
+	defer resp.Body.Close()
+	d.Response, _ = io.ReadAll(io.LimitReader(resp.Body, responseExcerptLimit))
+	_, _ = io.Copy(io.Discard, resp.Body)
+	d.StatusCode = resp.StatusCode
+	return d
+}
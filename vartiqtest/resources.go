@@ -0,0 +1,343 @@
+package vartiqtest
+
+import (
+	"net/http"
+
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+)
+
+func (s *Server) handleProjects(w http.ResponseWriter, r *http.Request, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case id == "" && r.Method == http.MethodPost:
+		var req vartiq.CreateProjectRequest
+		if !decode(w, r, &req) {
+			return
+		}
+		if fields := required("name", req.Name); fields != nil {
+			writeValidationError(w, http.StatusBadRequest, "Validation failed", fields)
+			return
+		}
+		now := s.timestamp()
+		p := &vartiq.Project{ID: s.newID(), Name: req.Name, Description: req.Description, CreatedAt: now, UpdatedAt: now}
+		s.projects.put(p.ID, p)
+		writeData(w, http.StatusCreated, "Project created successfully", p)
+	case id == "" && r.Method == http.MethodGet:
+		writeData(w, http.StatusOK, "Projects retrieved successfully", s.projects.all(nil))
+	case id == "":
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	default:
+		p, ok := s.projects.get(id)
+		if !ok {
+			writeError(w, http.StatusNotFound, "Project not found")
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			writeData(w, http.StatusOK, "Project retrieved successfully", p)
+		case http.MethodPut:
+			var req vartiq.UpdateProjectRequest
+			if !decode(w, r, &req) {
+				return
+			}
+			if req.Name != "" {
+				p.Name = req.Name
+			}
+			if req.Description != "" {
+				p.Description = req.Description
+			}
+			p.UpdatedAt = s.timestamp()
+			writeData(w, http.StatusOK, "Project updated successfully", p)
+		case http.MethodDelete:
+			for _, app := range s.apps.all(func(a *vartiq.App) bool { return s.appProject[a.ID] == id }) {
+				s.deleteApp(app.ID)
+			}
+			s.projects.delete(id)
+			writeData(w, http.StatusOK, "Project deleted successfully", nil)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+	}
+}
+
+func (s *Server) handleApps(w http.ResponseWriter, r *http.Request, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case id == "" && r.Method == http.MethodPost:
+		var req vartiq.CreateAppRequest
+		if !decode(w, r, &req) {
+			return
+		}
+		if fields := required("name", req.Name, "projectId", req.ProjectID); fields != nil {
+			writeValidationError(w, http.StatusBadRequest, "Validation failed", fields)
+			return
+		}
+		if _, ok := s.projects.get(req.ProjectID); !ok {
+			writeError(w, http.StatusNotFound, "Project not found")
+			return
+		}
+		now := s.timestamp()
+		a := &vartiq.App{ID: s.newID(), Name: req.Name, Description: req.Description, CreatedAt: now, UpdatedAt: now}
+		s.apps.put(a.ID, a)
+		s.appProject[a.ID] = req.ProjectID
+		writeData(w, http.StatusCreated, "App created successfully", a)
+	case id == "" && r.Method == http.MethodGet:
+		projectID := r.URL.Query().Get("projectId")
+		apps := s.apps.all(func(a *vartiq.App) bool { return projectID == "" || s.appProject[a.ID] == projectID })
+		writeData(w, http.StatusOK, "Apps retrieved successfully", apps)
+	case id == "":
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	default:
+		a, ok := s.apps.get(id)
+		if !ok {
+			writeError(w, http.StatusNotFound, "App not found")
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			writeData(w, http.StatusOK, "App retrieved successfully", a)
+		case http.MethodPut:
+			var req vartiq.UpdateAppRequest
+			if !decode(w, r, &req) {
+				return
+			}
+			if req.Name != "" {
+				a.Name = req.Name
+			}
+			if req.Description != "" {
+				a.Description = req.Description
+			}
+			a.UpdatedAt = s.timestamp()
+			writeData(w, http.StatusOK, "App updated successfully", a)
+		case http.MethodDelete:
+			s.deleteApp(id)
+			writeData(w, http.StatusOK, "App deleted successfully", nil)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+	}
+}
+
+// deleteApp removes an app with its webhooks and event types. Callers hold
+// s.mu.
+func (s *Server) deleteApp(id string) {
+	for _, wh := range s.webhooks.all(func(wh *vartiq.Webhook) bool { return wh.AppID == id }) {
+		s.webhooks.delete(wh.ID)
+	}
+	for _, et := range s.eventTypes.all(func(et *vartiq.EventType) bool { return et.AppID == id }) {
+		s.eventTypes.delete(et.ID)
+	}
+	s.apps.delete(id)
+	delete(s.appProject, id)
+}
+
+// webhookFields is the request body accepted by POST and PUT /webhooks.
+// Pointers distinguish omitted fields in updates.
+type webhookFields struct {
+	URL           *string          `json:"url"`
+	AppID         *string          `json:"appId"`
+	CustomHeaders *[]vartiq.Header `json:"customHeaders"`
+	AuthMethod    *string          `json:"authMethod"`
+	UserName      *string          `json:"userName"`
+	Password      *string          `json:"password"`
+	APIKey        *string          `json:"apiKey"`
+	APIKeyHeader  *string          `json:"apiKeyHeader"`
+	HMACHeader    *string          `json:"hmacHeader"`
+	HMACSecret    *string          `json:"hmacSecret"`
+	EventTypes    *[]string        `json:"eventTypes"`
+}
+
+func str(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
+
+// apply copies the provided fields onto wh, returning validation failures.
+func (f *webhookFields) apply(wh *vartiq.Webhook) []vartiq.FieldError {
+	if f.URL != nil {
+		wh.URL = *f.URL
+	}
+	if f.CustomHeaders != nil {
+		wh.CustomHeaders = *f.CustomHeaders
+	}
+	if f.EventTypes != nil {
+		wh.EventTypes = *f.EventTypes
+	}
+	if f.AuthMethod == nil {
+		return required("url", wh.URL)
+	}
+
+	auth := &vartiq.WebhookAuth{Method: vartiq.AuthMethod(*f.AuthMethod)}
+	var fields []vartiq.FieldError
+	switch auth.Method {
+	case "":
+		auth = nil
+	case vartiq.AuthMethodBasic:
+		auth.UserName, auth.Password = str(f.UserName), str(f.Password)
+		fields = required("userName", auth.UserName, "password", auth.Password)
+	case vartiq.AuthMethodAPIKey:
+		auth.APIKey, auth.APIKeyHeader = str(f.APIKey), str(f.APIKeyHeader)
+		fields = required("apiKey", auth.APIKey, "apiKeyHeader", auth.APIKeyHeader)
+	case vartiq.AuthMethodHMAC:
+		auth.HMACHeader, auth.HMACSecret = str(f.HMACHeader), str(f.HMACSecret)
+		fields = required("hmacHeader", auth.HMACHeader, "hmacSecret", auth.HMACSecret)
+	default:
+		fields = []vartiq.FieldError{{Field: "authMethod", Message: "must be one of basic, apiKey, hmac"}}
+	}
+	wh.AuthMethod = auth
+	return append(required("url", wh.URL), fields...)
+}
+
+func (s *Server) handleWebhooks(w http.ResponseWriter, r *http.Request, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case id == "" && r.Method == http.MethodPost:
+		var req webhookFields
+		if !decode(w, r, &req) {
+			return
+		}
+		wh := &vartiq.Webhook{AppID: str(req.AppID), CustomHeaders: []vartiq.Header{}, Headers: []vartiq.Header{}}
+		fields := append(required("appId", wh.AppID), req.apply(wh)...)
+		if len(fields) > 0 {
+			writeValidationError(w, http.StatusBadRequest, "Validation failed", fields)
+			return
+		}
+		if _, ok := s.apps.get(wh.AppID); !ok {
+			writeError(w, http.StatusNotFound, "App not found")
+			return
+		}
+		if fields := s.checkSubscriptions(wh.AppID, wh.EventTypes, nil); fields != nil {
+			writeValidationError(w, http.StatusBadRequest, "Validation failed", fields)
+			return
+		}
+		now := s.timestamp()
+		wh.ID, wh.CreatedAt, wh.UpdatedAt = s.newID(), now, now
+		s.webhooks.put(wh.ID, wh)
+		writeData(w, http.StatusCreated, "Webhook created successfully", wh)
+	case id == "" && r.Method == http.MethodGet:
+		appID := r.URL.Query().Get("appId")
+		webhooks := s.webhooks.all(func(wh *vartiq.Webhook) bool { return appID == "" || wh.AppID == appID })
+		writeData(w, http.StatusOK, "Webhooks retrieved successfully", webhooks)
+	case id == "":
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	default:
+		wh, ok := s.webhooks.get(id)
+		if !ok {
+			writeError(w, http.StatusNotFound, "Webhook not found")
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			writeData(w, http.StatusOK, "Webhook retrieved successfully", wh)
+		case http.MethodPut:
+			var req webhookFields
+			if !decode(w, r, &req) {
+				return
+			}
+			updated := *wh
+			fields := req.apply(&updated)
+			fields = append(fields, s.checkSubscriptions(wh.AppID, updated.EventTypes, wh.EventTypes)...)
+			if len(fields) > 0 {
+				writeValidationError(w, http.StatusBadRequest, "Validation failed", fields)
+				return
+			}
+			updated.UpdatedAt = s.timestamp()
+			*wh = updated
+			writeData(w, http.StatusOK, "Webhook updated successfully", wh)
+		case http.MethodDelete:
+			s.webhooks.delete(id)
+			writeData(w, http.StatusOK, "Webhook deleted successfully", nil)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+	}
+}
+
+// checkSubscriptions returns a field error unless every name is an event
+// type registered on the app that is not deprecated or already in current.
+// Callers hold s.mu.
+func (s *Server) checkSubscriptions(appID string, names, current []string) []vartiq.FieldError {
+	for _, name := range names {
+		et, ok := s.eventType(appID, name)
+		switch {
+		case !ok:
+			return []vartiq.FieldError{{Field: "eventTypes", Message: "unknown event type " + name}}
+		case et.Deprecated && !contains(current, name):
+			return []vartiq.FieldError{{Field: "eventTypes", Message: "event type " + name + " is deprecated"}}
+		}
+	}
+	return nil
+}
+
+// eventType looks up an event type of the app by name. Callers hold s.mu.
+func (s *Server) eventType(appID, name string) (*vartiq.EventType, bool) {
+	for _, id := range s.eventTypes.ids {
+		if et := s.eventTypes.rows[id]; et.AppID == appID && et.Name == name {
+			return et, true
+		}
+	}
+	return nil, false
+}
+
+func (s *Server) handleEventTypes(w http.ResponseWriter, r *http.Request, id, sub string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case id == "" && r.Method == http.MethodPost:
+		var req vartiq.CreateEventTypeRequest
+		if !decode(w, r, &req) {
+			return
+		}
+		if fields := required("appId", req.AppID, "name", req.Name); fields != nil {
+			writeValidationError(w, http.StatusBadRequest, "Validation failed", fields)
+			return
+		}
+		if _, ok := s.apps.get(req.AppID); !ok {
+			writeError(w, http.StatusNotFound, "App not found")
+			return
+		}
+		if _, ok := s.eventType(req.AppID, req.Name); ok {
+			writeError(w, http.StatusConflict, "Event type already exists")
+			return
+		}
+		now := s.timestamp()
+		et := &vartiq.EventType{
+			ID: s.newID(), AppID: req.AppID, Name: req.Name, Description: req.Description,
+			Schema: req.Schema, CreatedAt: now, UpdatedAt: now,
+		}
+		s.eventTypes.put(et.ID, et)
+		writeData(w, http.StatusCreated, "Event type created successfully", et)
+	case id == "" && r.Method == http.MethodGet:
+		appID := r.URL.Query().Get("appId")
+		if fields := required("appId", appID); fields != nil {
+			writeValidationError(w, http.StatusBadRequest, "Validation failed", fields)
+			return
+		}
+		eventTypes := s.eventTypes.all(func(et *vartiq.EventType) bool { return et.AppID == appID })
+		writeData(w, http.StatusOK, "Event types retrieved successfully", eventTypes)
+	case sub == "deprecate" && r.Method == http.MethodPost:
+		et, ok := s.eventTypes.get(id)
+		if !ok {
+			writeError(w, http.StatusNotFound, "Event type not found")
+			return
+		}
+		if !et.Deprecated {
+			et.Deprecated = true
+			et.UpdatedAt = s.timestamp()
+		}
+		writeData(w, http.StatusOK, "Event type deprecated successfully", et)
+	case id == "" || sub == "deprecate":
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	default:
+		writeError(w, http.StatusNotFound, "Route not found")
+	}
+}
@@ -0,0 +1,410 @@
+// Package vartiqtest provides an in-memory fake of the Vartiq API for
+// hermetic tests.
+//
+// A Server implements the /projects, /apps, /webhooks, /event-types and
+// /webhook-messages endpoints with the same envelopes and error responses as the real API, and
+// delivers created messages to the registered webhook URLs, signed with each
+// webhook's HMAC secret:
+//
+//	srv := vartiqtest.NewServer()
+//	defer srv.Close()
+//
+//	client := srv.Client()
+//	app, _ := client.App.Create(ctx, &vartiq.CreateAppRequest{...})
+package vartiqtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+)
+
+// DefaultAPIKey is the API key a Server accepts unless WithAPIKey is used.
+const DefaultAPIKey = "vartiqtest-api-key"
+
+// ServerOption configures a Server created with NewServer.
+type ServerOption func(*Server)
+
+// WithAPIKey sets the API key the server accepts in the x-api-key header.
+func WithAPIKey(apiKey string) ServerOption {
+	return func(s *Server) {
+		s.apiKey = apiKey
+	}
+}
+
+// WithDeliveryClient sets the HTTP client used to deliver messages to
+// webhook URLs.
+func WithDeliveryClient(hc *http.Client) ServerOption {
+	return func(s *Server) {
+		s.deliveryClient = hc
+	}
+}
+
+// WithClock sets the function used for timestamps.
+func WithClock(now func() time.Time) ServerOption {
+	return func(s *Server) {
+		s.now = now
+	}
+}
+
+// signatureScheme selects how deliveries to HMAC webhooks are signed.
+type signatureScheme int
+
+const (
+	signBody signatureScheme = iota
+	signTimestamped
+	signStandard
+)
+
+// WithTimestampedSignatures makes the server sign deliveries to HMAC
+// webhooks with vartiq.SignWithTimestamp, sending the message ID and signing
+// time in the vartiq.MessageIDHeader and vartiq.TimestampHeader headers, as
+// verified by vartiq.Client.VerifyWithOptions. By default deliveries are
+// signed over the body only, as verified by vartiq.Client.Verify.
+func WithTimestampedSignatures() ServerOption {
+	return func(s *Server) {
+		s.signatures = signTimestamped
+	}
+}
+
+// WithStandardSignatures makes the server sign deliveries to HMAC webhooks
+// as specified by Standard Webhooks, with vartiq.SignStandard and the
+// webhook-id, webhook-timestamp and webhook-signature headers, as verified by
+// vartiq.Client.VerifyStandard. The webhooks' HMAC secrets must be whsec_
+// secrets and their HMAC header is ignored. When combined with
+// WithTimestampedSignatures, the option given last applies.
+func WithStandardSignatures() ServerOption {
+	return func(s *Server) {
+		s.signatures = signStandard
+	}
+}
+
+// Server is a fake Vartiq API backed by in-memory state. It is safe for
+// concurrent use.
+type Server struct {
+	// URL is the base URL of the server, suitable for vartiq.WithBaseURL.
+	URL string
+
+	srv            *httptest.Server
+	apiKey         string
+	deliveryClient *http.Client
+	now            func() time.Time
+	signatures     signatureScheme
+
+	mu         sync.Mutex
+	seq        int
+	projects   table[vartiq.Project]
+	apps       table[vartiq.App]
+	appProject map[string]string
+	webhooks   table[vartiq.Webhook]
+	eventTypes table[vartiq.EventType]
+	messages   table[message]
+	deliveries []Delivery
+	idempotent map[string]*idempotentRequest
+	failures   []int
+	// inFlight counts the deliveries running; idle is signaled, with mu
+	// held, when it drops to zero.
+	inFlight  int
+	idle      *sync.Cond
+	closed    bool
+	closeOnce sync.Once
+}
+
+// NewServer starts a fake Vartiq API. Call Close when done.
+func NewServer(opts ...ServerOption) *Server {
+	s := &Server{
+		apiKey:         DefaultAPIKey,
+		deliveryClient: &http.Client{Timeout: 10 * time.Second},
+		now:            time.Now,
+		idempotent:     make(map[string]*idempotentRequest),
+		appProject:     make(map[string]string),
+	}
+	s.idle = sync.NewCond(&s.mu)
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.srv = httptest.NewServer(s)
+	s.URL = s.srv.URL
+	return s
+}
+
+// Close waits for in-flight deliveries and shuts the server down.
+func (s *Server) Close() {
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		s.closed = true
+		s.waitIdle()
+		s.mu.Unlock()
+		s.srv.Close()
+	})
+}
+
+// Client returns a vartiq client pointed at the server with a valid API key
+// and retries disabled. opts are applied after those defaults.
+func (s *Server) Client(opts ...vartiq.Option) *vartiq.Client {
+	opts = append([]vartiq.Option{
+		vartiq.WithBaseURL(s.URL),
+		vartiq.WithRetryPolicy(vartiq.RetryPolicy{}),
+	}, opts...)
+	return vartiq.NewClient(s.apiKey, opts...)
+}
+
+// FailNext makes the next n API requests fail with status, to exercise
+// error handling and retries.
+func (s *Server) FailNext(n, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < n; i++ {
+		s.failures = append(s.failures, status)
+	}
+}
+
+// Wait blocks until every delivery started so far has completed.
+func (s *Server) Wait() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.waitIdle()
+}
+
+// waitIdle waits for in-flight deliveries to finish. Callers hold s.mu.
+func (s *Server) waitIdle() {
+	for s.inFlight > 0 {
+		s.idle.Wait()
+	}
+}
+
+// Deliveries returns every delivery attempt made so far, oldest first.
+func (s *Server) Deliveries() []Delivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Delivery(nil), s.deliveries...)
+}
+
+// recordedResponse is a response stored for idempotent replay.
+type recordedResponse struct {
+	status int
+	body   []byte
+}
+
+// idempotentRequest reserves an idempotency key for the request handling
+// it. done is closed once it has finished; response is set if it succeeded.
+type idempotentRequest struct {
+	done     chan struct{}
+	response *recordedResponse
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.seq++
+	w.Header().Set("X-Request-Id", fmt.Sprintf("req_%d", s.seq))
+	var failure int
+	if len(s.failures) > 0 {
+		failure, s.failures = s.failures[0], s.failures[1:]
+	}
+	s.mu.Unlock()
+
+	if r.Header.Get("x-api-key") != s.apiKey {
+		writeError(w, http.StatusUnauthorized, "Invalid API key")
+		return
+	}
+	if failure != 0 {
+		writeError(w, failure, http.StatusText(failure))
+		return
+	}
+
+	key := r.Header.Get(vartiq.IdempotencyKeyHeader)
+	if r.Method != http.MethodPost || key == "" {
+		s.route(w, r)
+		return
+	}
+
+	// Requests with the same key run one at a time: the first reserves the
+	// key and the others wait for its outcome, replaying it on success and
+	// trying themselves otherwise.
+	cacheKey := r.URL.Path + "\x00" + key
+	var req *idempotentRequest
+	for {
+		s.mu.Lock()
+		prev, ok := s.idempotent[cacheKey]
+		if !ok {
+			req = &idempotentRequest{done: make(chan struct{})}
+			s.idempotent[cacheKey] = req
+		}
+		s.mu.Unlock()
+		if !ok {
+			break
+		}
+
+		select {
+		case <-prev.done:
+		case <-r.Context().Done():
+			return
+		}
+		if prev.response != nil {
+			w.Header().Set("Idempotent-Replayed", "true")
+			writeRaw(w, prev.response.status, prev.response.body)
+			return
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	s.route(rec, r)
+	s.mu.Lock()
+	if rec.Code >= 200 && rec.Code < 300 {
+		req.response = &recordedResponse{status: rec.Code, body: rec.Body.Bytes()}
+	} else {
+		delete(s.idempotent, cacheKey)
+	}
+	s.mu.Unlock()
+	close(req.done)
+	writeRaw(w, rec.Code, rec.Body.Bytes())
+}
+
+func (s *Server) route(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	id, sub := "", ""
+	if len(parts) > 1 {
+		id = parts[1]
+	}
+	if len(parts) > 2 {
+		sub = parts[2]
+	}
+	if len(parts) > 3 || (sub != "" && parts[0] != "webhook-messages" && parts[0] != "event-types") {
+		writeError(w, http.StatusNotFound, "Route not found")
+		return
+	}
+
+	switch parts[0] {
+	case "projects":
+		s.handleProjects(w, r, id)
+	case "apps":
+		s.handleApps(w, r, id)
+	case "webhooks":
+		s.handleWebhooks(w, r, id)
+	case "event-types":
+		s.handleEventTypes(w, r, id, sub)
+	case "webhook-messages":
+		s.handleWebhookMessages(w, r, id, sub)
+	default:
+		writeError(w, http.StatusNotFound, "Route not found")
+	}
+}
+
+// newID returns a unique, ObjectID-shaped identifier. Callers hold s.mu.
+func (s *Server) newID() string {
+	s.seq++
+	return fmt.Sprintf("%08x%016x", s.now().Unix(), s.seq)
+}
+
+// timestamp formats the current time like the API does. Callers hold s.mu.
+func (s *Server) timestamp() string {
+	return formatTime(s.now())
+}
+
+// formatTime formats t like the API does.
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+// envelope is the body of every successful response.
+type envelope struct {
+	Data    interface{} `json:"data"`
+	Message string      `json:"message"`
+	Success bool        `json:"success"`
+}
+
+func writeData(w http.ResponseWriter, status int, message string, data interface{}) {
+	body, _ := json.Marshal(envelope{Data: data, Message: message, Success: true})
+	writeRaw(w, status, body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeValidationError(w, status, message, nil)
+}
+
+func writeValidationError(w http.ResponseWriter, status int, message string, fields []vartiq.FieldError) {
+	body, _ := json.Marshal(struct {
+		Message string              `json:"message"`
+		Success bool                `json:"success"`
+		Errors  []vartiq.FieldError `json:"errors,omitempty"`
+	}{Message: message, Errors: fields})
+	writeRaw(w, status, body)
+}
+
+func writeRaw(w http.ResponseWriter, status int, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+// decode reads a JSON request body into v, writing a 400 on failure.
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid JSON body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// required returns a field error for every empty value, given as name/value pairs.
+func required(pairs ...string) []vartiq.FieldError {
+	var fields []vartiq.FieldError
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] == "" {
+			fields = append(fields, vartiq.FieldError{Field: pairs[i], Message: "is required"})
+		}
+	}
+	return fields
+}
+
+// table is an insertion-ordered in-memory collection.
+type table[T any] struct {
+	ids  []string
+	rows map[string]*T
+}
+
+func (t *table[T]) put(id string, row *T) {
+	if t.rows == nil {
+		t.rows = make(map[string]*T)
+	}
+	if _, ok := t.rows[id]; !ok {
+		t.ids = append(t.ids, id)
+	}
+	t.rows[id] = row
+}
+
+func (t *table[T]) get(id string) (*T, bool) {
+	row, ok := t.rows[id]
+	return row, ok
+}
+
+func (t *table[T]) delete(id string) {
+	if _, ok := t.rows[id]; !ok {
+		return
+	}
+	delete(t.rows, id)
+	for i, v := range t.ids {
+		if v == id {
+			t.ids = append(t.ids[:i], t.ids[i+1:]...)
+			break
+		}
+	}
+}
+
+// all returns copies of the rows matching keep, in insertion order.
+func (t *table[T]) all(keep func(*T) bool) []T {
+	out := []T{}
+	for _, id := range t.ids {
+		if row := t.rows[id]; keep == nil || keep(row) {
+			out = append(out, *row)
+		}
+	}
+	return out
+}
@@ -0,0 +1,737 @@
+package vartiqtest
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq"
+	"github.com/vartiqhq/vartiq-go-sdk/vartiq/schema"
+)
+
+func setupApp(t *testing.T, client *vartiq.Client) (projectID, appID string) {
+	ctx := context.Background()
+	project, err := client.Project.Create(ctx, &vartiq.CreateProjectRequest{Name: "Project"})
+	require.NoError(t, err)
+	app, err := client.App.Create(ctx, &vartiq.CreateAppRequest{Name: "App", ProjectID: project.Data.ID})
+	require.NoError(t, err)
+	return project.Data.ID, app.Data.ID
+}
+
+func TestServer_ProjectAndAppLifecycle(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+
+	projectID, appID := setupApp(t, client)
+
+	projects, err := client.Project.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, projects.Data, 1)
+	assert.Equal(t, projectID, projects.Data[0].ID)
+	assert.NotEmpty(t, projects.RequestID)
+
+	updated, err := client.App.Update(ctx, appID, &vartiq.UpdateAppRequest{Name: "Renamed"})
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", updated.Data.Name)
+
+	apps, err := client.App.List(ctx, projectID)
+	require.NoError(t, err)
+	require.Len(t, apps.Data, 1)
+
+	require.NoError(t, client.Project.Delete(ctx, projectID))
+	_, err = client.App.Get(ctx, appID)
+	assert.ErrorIs(t, err, vartiq.ErrNotFound)
+}
+
+func TestServer_Errors(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+
+	_, err := srv.Client().Project.Get(ctx, "missing")
+	assert.ErrorIs(t, err, vartiq.ErrNotFound)
+
+	_, err = vartiq.NewClient("wrong-key", vartiq.WithBaseURL(srv.URL)).Project.List(ctx)
+	assert.ErrorIs(t, err, vartiq.ErrUnauthorized)
+
+	_, err = srv.Client().Project.Create(ctx, &vartiq.CreateProjectRequest{})
+	var validationErr *vartiq.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []vartiq.FieldError{{Field: "name", Message: "is required"}}, validationErr.Fields)
+}
+
+func TestServer_FailNextWithRetries(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	policy := vartiq.DefaultRetryPolicy()
+	policy.MinBackoff = time.Millisecond
+	client := srv.Client(vartiq.WithRetryPolicy(policy))
+
+	srv.FailNext(2, http.StatusServiceUnavailable)
+	_, err := client.Project.List(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestServer_DeliversSignedMessages(t *testing.T) {
+	const secret = "whsec-test"
+	var (
+		mu       sync.Mutex
+		received [][]byte
+		sigs     []string
+	)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, body)
+		sigs = append(sigs, r.Header.Get("X-Signature"))
+		mu.Unlock()
+	}))
+	defer receiver.Close()
+
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+	_, appID := setupApp(t, client)
+
+	webhook, err := client.Webhook.Create(ctx, &vartiq.CreateWebhookRequest{
+		URL:        receiver.URL,
+		AppID:      appID,
+		AuthMethod: string(vartiq.AuthMethodHMAC),
+		HMACHeader: "X-Signature",
+		HMACSecret: secret,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, vartiq.AuthMethodHMAC, webhook.Data.AuthMethod.Method)
+
+	msg, err := client.WebhookMessage.Create(ctx, appID, map[string]interface{}{"event": "user.created"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"event": "user.created"}, msg.Data.Payload)
+	assert.NotEmpty(t, msg.Data.Signature)
+
+	srv.Wait()
+	require.Len(t, received, 1)
+	verified, err := client.Verify(received[0], sigs[0], secret)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"event":"user.created"}`, string(verified))
+	assert.Equal(t, msg.Data.Signature, sigs[0])
+
+	deliveries := srv.Deliveries()
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, webhook.Data.ID, deliveries[0].WebhookID)
+	assert.Equal(t, http.StatusOK, deliveries[0].StatusCode)
+}
+
+func TestServer_TimestampedSignatures(t *testing.T) {
+	const secret = "whsec-test"
+	var (
+		mu      sync.Mutex
+		headers []http.Header
+		bodies  [][]byte
+	)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		headers = append(headers, r.Header.Clone())
+		bodies = append(bodies, body)
+		mu.Unlock()
+	}))
+	defer receiver.Close()
+
+	signedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	srv := NewServer(WithTimestampedSignatures(), WithClock(func() time.Time { return signedAt }))
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+	_, appID := setupApp(t, client)
+
+	_, err := client.Webhook.Create(ctx, &vartiq.CreateWebhookRequest{
+		URL:        receiver.URL,
+		AppID:      appID,
+		AuthMethod: string(vartiq.AuthMethodHMAC),
+		HMACHeader: "X-Signature",
+		HMACSecret: secret,
+	})
+	require.NoError(t, err)
+
+	msg, err := client.WebhookMessage.Create(ctx, appID, map[string]interface{}{"event": "user.created"})
+	require.NoError(t, err)
+	srv.Wait()
+	require.Len(t, bodies, 1)
+
+	h := headers[0]
+	assert.Equal(t, msg.Data.ID, h.Get(vartiq.MessageIDHeader))
+	assert.Equal(t, "1714564800", h.Get(vartiq.TimestampHeader))
+	assert.Equal(t, msg.Data.Signature, h.Get("X-Signature"))
+
+	opts := vartiq.VerifyOptions{
+		MessageID: h.Get(vartiq.MessageIDHeader),
+		Timestamp: h.Get(vartiq.TimestampHeader),
+		Now:       func() time.Time { return signedAt.Add(time.Minute) },
+	}
+	verified, err := client.VerifyWithOptions(bodies[0], h.Get("X-Signature"), secret, opts)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"event":"user.created"}`, string(verified))
+
+	// The same request replayed an hour later is rejected.
+	opts.Now = func() time.Time { return signedAt.Add(time.Hour) }
+	_, err = client.VerifyWithOptions(bodies[0], h.Get("X-Signature"), secret, opts)
+	assert.ErrorIs(t, err, vartiq.ErrSignatureExpired)
+
+	_, err = client.Verify(bodies[0], h.Get("X-Signature"), secret)
+	assert.ErrorIs(t, err, vartiq.ErrSignatureInvalid)
+}
+
+func TestServer_StandardSignatures(t *testing.T) {
+	const secret = "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw"
+	var (
+		mu      sync.Mutex
+		headers []http.Header
+		bodies  [][]byte
+	)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		headers = append(headers, r.Header.Clone())
+		bodies = append(bodies, body)
+		mu.Unlock()
+	}))
+	defer receiver.Close()
+
+	signedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	srv := NewServer(WithStandardSignatures(), WithClock(func() time.Time { return signedAt }))
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+	_, appID := setupApp(t, client)
+
+	for _, hmacSecret := range []string{secret, "not a whsec secret"} {
+		_, err := client.Webhook.Create(ctx, &vartiq.CreateWebhookRequest{
+			URL:        receiver.URL,
+			AppID:      appID,
+			AuthMethod: string(vartiq.AuthMethodHMAC),
+			HMACHeader: "X-Signature",
+			HMACSecret: hmacSecret,
+		})
+		require.NoError(t, err)
+	}
+
+	msgs, err := client.WebhookMessage.Create(ctx, appID, map[string]interface{}{"event": "user.created"})
+	require.NoError(t, err)
+	srv.Wait()
+	// The webhook with an invalid secret is not delivered to.
+	require.Len(t, bodies, 1)
+
+	h := headers[0]
+	assert.Empty(t, h.Get("X-Signature"))
+	assert.Equal(t, msgs.Data.ID, h.Get(vartiq.StandardIDHeader))
+	assert.Equal(t, "1714564800", h.Get(vartiq.StandardTimestampHeader))
+	assert.Equal(t, msgs.Data.Signature, h.Get(vartiq.StandardSignatureHeader))
+	assert.Regexp(t, `^v1,[A-Za-z0-9+/]+=*$`, h.Get(vartiq.StandardSignatureHeader))
+
+	verified, err := client.VerifyStandard(bodies[0], h.Get(vartiq.StandardSignatureHeader), secret, vartiq.VerifyOptions{
+		MessageID: h.Get(vartiq.StandardIDHeader),
+		Timestamp: h.Get(vartiq.StandardTimestampHeader),
+		Now:       func() time.Time { return signedAt },
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"event":"user.created"}`, string(verified))
+
+	var failed int
+	for _, d := range srv.Deliveries() {
+		if d.Err != nil {
+			failed++
+			assert.ErrorContains(t, d.Err, "invalid Standard Webhooks secret")
+		}
+	}
+	assert.Equal(t, 1, failed)
+}
+
+func TestServer_IdempotentReplay(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+	_, appID := setupApp(t, client)
+
+	first, err := client.WebhookMessage.Create(ctx, appID, "hello", vartiq.WithIdempotencyKey("evt-1"))
+	require.NoError(t, err)
+	assert.False(t, first.Replayed)
+
+	second, err := client.WebhookMessage.Create(ctx, appID, "hello", vartiq.WithIdempotencyKey("evt-1"))
+	require.NoError(t, err)
+	assert.True(t, second.Replayed)
+	assert.Equal(t, first.Data.ID, second.Data.ID)
+}
+
+func TestServer_ConcurrentIdempotentRequests(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+	_, appID := setupApp(t, client)
+
+	post := func(body io.Reader) (*http.Response, error) {
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/webhook-messages", body)
+		require.NoError(t, err)
+		req.Header.Set("x-api-key", DefaultAPIKey)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(vartiq.IdempotencyKeyHeader, "evt-1")
+		return http.DefaultClient.Do(req)
+	}
+
+	// The first request holds the key while its body is still arriving.
+	body, writer := io.Pipe()
+	defer writer.Close()
+	first := make(chan *http.Response)
+	go func() {
+		resp, err := post(body)
+		assert.NoError(t, err)
+		first <- resp
+	}()
+	require.Eventually(t, func() bool {
+		srv.mu.Lock()
+		defer srv.mu.Unlock()
+		return len(srv.idempotent) == 1
+	}, time.Second, time.Millisecond)
+
+	second := make(chan *http.Response)
+	go func() {
+		resp, err := post(strings.NewReader(`{"appId":"` + appID + `","payload":"hello"}`))
+		assert.NoError(t, err)
+		second <- resp
+	}()
+	select {
+	case <-second:
+		t.Fatal("the second request ran while the first held the key")
+	case <-time.After(50 * time.Millisecond):
+	}
+	_, err := writer.Write([]byte(`{"appId":"` + appID + `","payload":"hello"}`))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	for i, resp := range []*http.Response{<-first, <-second} {
+		require.NotNil(t, resp)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, i == 1, resp.Header.Get("Idempotent-Replayed") == "true")
+		resp.Body.Close()
+	}
+	messages, err := client.WebhookMessage.List(ctx, appID, nil)
+	require.NoError(t, err)
+	assert.Len(t, messages.Data, 1, "the second request waits for the first and replays it")
+}
+
+func TestServer_WaitWhileDeliveriesStart(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer receiver.Close()
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+	_, appID := setupApp(t, client)
+	_, err := client.Webhook.Create(ctx, &vartiq.CreateWebhookRequest{URL: receiver.URL, AppID: appID})
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			_, err := client.WebhookMessage.Create(ctx, appID, i)
+			assert.NoError(t, err)
+		}
+	}()
+	for waiting := true; waiting; {
+		select {
+		case <-done:
+			waiting = false
+		default:
+			srv.Wait()
+		}
+	}
+	srv.Wait()
+	assert.Len(t, srv.Deliveries(), 20)
+}
+
+func TestServer_WebhookUpdate(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+	_, appID := setupApp(t, client)
+
+	webhook, err := client.Webhook.Create(ctx, &vartiq.CreateWebhookRequest{
+		URL:           "https://example.com/hook",
+		AppID:         appID,
+		CustomHeaders: []vartiq.Header{{Key: "X-App", Value: "1"}},
+	})
+	require.NoError(t, err)
+
+	updated, err := client.Webhook.UpdateWebhook(ctx, webhook.Data.ID, &vartiq.UpdateWebhookRequest{
+		URL:           vartiq.Ptr("https://example.com/new"),
+		CustomHeaders: &[]vartiq.Header{},
+		AuthMethod:    vartiq.Ptr(string(vartiq.AuthMethodAPIKey)),
+		APIKey:        vartiq.Ptr("k"),
+		APIKeyHeader:  vartiq.Ptr("X-Key"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/new", updated.Data.URL)
+	assert.Empty(t, updated.Data.CustomHeaders)
+	assert.Equal(t, vartiq.AuthMethodAPIKey, updated.Data.AuthMethod.Method)
+	assert.Equal(t, "X-Key", updated.Data.AuthMethod.APIKeyHeader)
+}
+
+func TestServer_ListAndGetMessages(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var clockMu sync.Mutex
+	clock := func() time.Time {
+		clockMu.Lock()
+		defer clockMu.Unlock()
+		return now
+	}
+	srv := NewServer(WithClock(clock))
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+	_, appID := setupApp(t, client)
+
+	for _, typ := range []string{"invoice.paid", "user.deleted"} {
+		_, err := client.EventType.Create(ctx, &vartiq.CreateEventTypeRequest{AppID: appID, Name: typ})
+		require.NoError(t, err)
+	}
+
+	var ids []string
+	for _, typ := range []string{"invoice.paid", "user.deleted", "invoice.paid"} {
+		msg, err := client.WebhookMessage.Create(ctx, appID, map[string]interface{}{"type": typ}, vartiq.WithEventType(typ))
+		require.NoError(t, err)
+		ids = append(ids, msg.Data.ID)
+		clockMu.Lock()
+		now = now.Add(time.Minute)
+		clockMu.Unlock()
+	}
+
+	all, err := client.WebhookMessage.List(ctx, appID, nil)
+	require.NoError(t, err)
+	require.Len(t, all.Data, 3)
+	assert.Equal(t, ids[2], all.Data[0].ID, "newest first")
+
+	paid, err := client.WebhookMessage.List(ctx, appID, &vartiq.WebhookMessageFilter{EventType: "invoice.paid"})
+	require.NoError(t, err)
+	assert.Len(t, paid.Data, 2)
+
+	window, err := client.WebhookMessage.List(ctx, appID, &vartiq.WebhookMessageFilter{
+		Since: time.Date(2024, 5, 1, 12, 1, 0, 0, time.UTC),
+		Until: time.Date(2024, 5, 1, 12, 2, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	require.Len(t, window.Data, 1)
+	assert.Equal(t, ids[1], window.Data[0].ID)
+
+	page, err := client.WebhookMessage.List(ctx, appID, &vartiq.WebhookMessageFilter{Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	require.Len(t, page.Data, 1)
+	assert.Equal(t, ids[1], page.Data[0].ID)
+
+	delivered, err := client.WebhookMessage.List(ctx, appID, &vartiq.WebhookMessageFilter{Delivered: vartiq.Ptr(true)})
+	require.NoError(t, err)
+	assert.Empty(t, delivered.Data)
+
+	got, err := client.WebhookMessage.Get(ctx, ids[0])
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"type": "invoice.paid"}, got.Data.Payload)
+	assert.Equal(t, appID, got.Data.AppID)
+
+	_, err = client.WebhookMessage.Get(ctx, "missing")
+	assert.ErrorIs(t, err, vartiq.ErrNotFound)
+
+	_, err = client.WebhookMessage.Create(ctx, appID, map[string]interface{}{"type": "invoice.paid"})
+	require.NoError(t, err)
+	paid, err = client.WebhookMessage.List(ctx, appID, &vartiq.WebhookMessageFilter{EventType: "invoice.paid"})
+	require.NoError(t, err)
+	assert.Len(t, paid.Data, 2, "the payload's type field is not an event type")
+}
+
+func TestServer_ListAttempts(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte("try later"))
+	}))
+	defer receiver.Close()
+
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+	_, appID := setupApp(t, client)
+
+	webhook, err := client.Webhook.Create(ctx, &vartiq.CreateWebhookRequest{URL: receiver.URL, AppID: appID})
+	require.NoError(t, err)
+	msg, err := client.WebhookMessage.Create(ctx, appID, map[string]interface{}{"type": "user.created"})
+	require.NoError(t, err)
+	srv.Wait()
+
+	attempts, err := client.WebhookMessage.ListAttempts(ctx, msg.Data.ID)
+	require.NoError(t, err)
+	require.Len(t, attempts.Data, 1)
+	a := attempts.Data[0]
+	assert.Equal(t, webhook.Data.ID, a.WebhookID)
+	assert.Equal(t, receiver.URL, a.URL)
+	assert.Equal(t, 1, a.Attempt)
+	assert.Equal(t, http.StatusServiceUnavailable, a.StatusCode)
+	assert.Equal(t, "try later", a.ResponseBody)
+	assert.False(t, a.Succeeded())
+	assert.False(t, a.Timestamp.IsZero())
+
+	_, err = client.WebhookMessage.ListAttempts(ctx, "missing")
+	assert.ErrorIs(t, err, vartiq.ErrNotFound)
+}
+
+func TestServer_RetryAndReplay(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		down = true
+	)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if down {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer receiver.Close()
+
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+	_, appID := setupApp(t, client)
+
+	webhook, err := client.Webhook.Create(ctx, &vartiq.CreateWebhookRequest{URL: receiver.URL, AppID: appID})
+	require.NoError(t, err)
+	start := time.Now().Add(-time.Minute)
+	var ids []string
+	for i := 0; i < 3; i++ {
+		msg, err := client.WebhookMessage.Create(ctx, appID, map[string]interface{}{"n": i})
+		require.NoError(t, err)
+		ids = append(ids, msg.Data.ID)
+	}
+	srv.Wait()
+
+	mu.Lock()
+	down = false
+	mu.Unlock()
+
+	retried, err := client.WebhookMessage.Retry(ctx, ids[0], nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{webhook.Data.ID}, retried.Data.WebhookIDs)
+	srv.Wait()
+	attempts, err := client.WebhookMessage.ListAttempts(ctx, ids[0])
+	require.NoError(t, err)
+	require.Len(t, attempts.Data, 2)
+	assert.Equal(t, 2, attempts.Data[1].Attempt)
+	assert.True(t, attempts.Data[1].Succeeded())
+
+	_, err = client.WebhookMessage.Retry(ctx, ids[0], &vartiq.RetryMessageOptions{WebhookIDs: []string{"other"}})
+	assert.ErrorIs(t, err, vartiq.ErrValidation)
+
+	var progress []vartiq.ReplayProgress
+	result, err := client.WebhookMessage.Replay(ctx, appID, start, time.Now().Add(time.Minute), &vartiq.ReplayFilter{
+		Delivered:  vartiq.Ptr(false),
+		OnProgress: func(p vartiq.ReplayProgress) { progress = append(progress, p) },
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Succeeded)
+	assert.Zero(t, result.Failed)
+	assert.Equal(t, ids[1], result.Items[0].MessageID)
+	assert.Len(t, progress, 2)
+	srv.Wait()
+
+	undelivered, err := client.WebhookMessage.List(ctx, appID, &vartiq.WebhookMessageFilter{Delivered: vartiq.Ptr(false)})
+	require.NoError(t, err)
+	assert.Empty(t, undelivered.Data)
+}
+
+func TestServer_WaitForDelivery(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer receiver.Close()
+
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, appID := setupApp(t, client)
+
+	_, err := client.Webhook.Create(ctx, &vartiq.CreateWebhookRequest{URL: receiver.URL, AppID: appID})
+	require.NoError(t, err)
+	msg, err := client.WebhookMessage.Create(ctx, appID, map[string]interface{}{"type": "user.created"})
+	require.NoError(t, err)
+
+	status, err := client.WebhookMessage.WaitForDelivery(ctx, msg.Data.ID, &vartiq.WaitOptions{PollInterval: 5 * time.Millisecond})
+	require.NoError(t, err)
+	assert.True(t, status.Message.IsDelivered)
+	require.Len(t, status.Attempts, 1)
+	assert.True(t, status.Attempts[0].Succeeded())
+}
+
+func TestServer_CreateBatch(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received int
+	)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received++
+		mu.Unlock()
+	}))
+	defer receiver.Close()
+
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+	_, appID := setupApp(t, client)
+	_, err := client.Webhook.Create(ctx, &vartiq.CreateWebhookRequest{URL: receiver.URL, AppID: appID})
+	require.NoError(t, err)
+
+	results, err := client.WebhookMessage.CreateBatch(ctx, appID, []interface{}{
+		map[string]interface{}{"n": 1}, nil, map[string]interface{}{"n": 3},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, map[string]interface{}{"n": float64(1)}, results[0].Message.Payload)
+	assert.ErrorIs(t, results[1].Err, vartiq.ErrValidation)
+	assert.NoError(t, results[2].Err)
+
+	srv.Wait()
+	assert.Equal(t, 2, received)
+
+	results, err = client.WebhookMessage.CreateBatch(ctx, "missing", []interface{}{"x"})
+	require.NoError(t, err)
+	assert.ErrorIs(t, results[0].Err, vartiq.ErrNotFound)
+}
+
+func TestServer_EventTypes(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received = map[string][]string{}
+	)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received[r.URL.Path] = append(received[r.URL.Path], string(body))
+		mu.Unlock()
+	}))
+	defer receiver.Close()
+
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+	_, appID := setupApp(t, client)
+
+	paid, err := client.EventType.Create(ctx, &vartiq.CreateEventTypeRequest{AppID: appID, Name: "invoice.paid"})
+	require.NoError(t, err)
+	deleted, err := client.EventType.Create(ctx, &vartiq.CreateEventTypeRequest{AppID: appID, Name: "user.deleted"})
+	require.NoError(t, err)
+	_, err = client.EventType.Create(ctx, &vartiq.CreateEventTypeRequest{AppID: appID, Name: "invoice.paid"})
+	assert.ErrorIs(t, err, vartiq.ErrConflict)
+
+	billing, err := client.Webhook.Create(ctx, &vartiq.CreateWebhookRequest{
+		URL: receiver.URL + "/billing", AppID: appID, EventTypes: []string{paid.Data.Name},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"invoice.paid"}, billing.Data.EventTypes)
+	_, err = client.Webhook.Create(ctx, &vartiq.CreateWebhookRequest{URL: receiver.URL + "/all", AppID: appID})
+	require.NoError(t, err)
+	_, err = client.Webhook.Create(ctx, &vartiq.CreateWebhookRequest{
+		URL: receiver.URL + "/x", AppID: appID, EventTypes: []string{"order.shipped"},
+	})
+	assert.ErrorIs(t, err, vartiq.ErrValidation)
+
+	_, err = client.WebhookMessage.Create(ctx, appID, map[string]int{"n": 1}, vartiq.WithEventType("invoice.paid"))
+	require.NoError(t, err)
+	_, err = client.WebhookMessage.Create(ctx, appID, map[string]int{"n": 2}, vartiq.WithEventType("user.deleted"))
+	require.NoError(t, err)
+	_, err = client.WebhookMessage.Create(ctx, appID, map[string]int{"n": 3}, vartiq.WithEventType("order.shipped"))
+	assert.ErrorIs(t, err, vartiq.ErrValidation)
+	srv.Wait()
+	assert.Equal(t, []string{`{"n":1}`}, received["/billing"])
+	assert.Equal(t, []string{`{"n":1}`, `{"n":2}`}, received["/all"])
+
+	messages, err := client.WebhookMessage.List(ctx, appID, &vartiq.WebhookMessageFilter{EventType: "user.deleted"})
+	require.NoError(t, err)
+	require.Len(t, messages.Data, 1)
+	assert.Equal(t, "user.deleted", messages.Data[0].EventType)
+
+	_, err = client.EventType.Deprecate(ctx, deleted.Data.ID)
+	require.NoError(t, err)
+	list, err := client.EventType.List(ctx, appID)
+	require.NoError(t, err)
+	require.Len(t, list.Data, 2)
+	assert.True(t, list.Data[1].Deprecated)
+
+	// New subscriptions to a deprecated event type are refused.
+	_, err = client.Webhook.UpdateWebhook(ctx, billing.Data.ID, &vartiq.UpdateWebhookRequest{
+		EventTypes: &[]string{"invoice.paid", "user.deleted"},
+	})
+	assert.ErrorIs(t, err, vartiq.ErrValidation)
+	updated, err := client.Webhook.UpdateWebhook(ctx, billing.Data.ID, &vartiq.UpdateWebhookRequest{EventTypes: &[]string{}})
+	require.NoError(t, err)
+	assert.Empty(t, updated.Data.EventTypes)
+}
+
+func TestServer_EventTypeSchemas(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+	var receiverSchema *schema.Schema
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		errs = append(errs, receiverSchema.ValidateJSON(body))
+		mu.Unlock()
+	}))
+	defer receiver.Close()
+
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client(vartiq.WithSchemaValidation())
+	ctx := context.Background()
+	_, appID := setupApp(t, client)
+
+	created, err := client.EventType.Create(ctx, &vartiq.CreateEventTypeRequest{
+		AppID:  appID,
+		Name:   "user.created",
+		Schema: json.RawMessage(`{"type":"object","required":["email"],"properties":{"email":{"type":"string"}}}`),
+	})
+	require.NoError(t, err)
+	list, err := client.EventType.List(ctx, appID)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(created.Data.Schema), string(list.Data[0].Schema))
+
+	receiverSchema, err = schema.Compile(list.Data[0].Schema)
+	require.NoError(t, err)
+	_, err = client.Webhook.Create(ctx, &vartiq.CreateWebhookRequest{URL: receiver.URL, AppID: appID})
+	require.NoError(t, err)
+
+	_, err = client.WebhookMessage.Create(ctx, appID, map[string]string{"name": "x"}, vartiq.WithEventType("user.created"))
+	assert.ErrorIs(t, err, vartiq.ErrValidation)
+	_, err = client.WebhookMessage.Create(ctx, appID, map[string]string{"email": "a@b.c"}, vartiq.WithEventType("user.created"))
+	require.NoError(t, err)
+
+	srv.Wait()
+	assert.Equal(t, []error{nil}, errs)
+	assert.Len(t, srv.Deliveries(), 1)
+}